@@ -0,0 +1,223 @@
+package postgrest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// BatchOptions contains options for InsertBatch and UpsertBatch
+type BatchOptions struct {
+	BatchSize   int // rows per request, defaults to 1000
+	MaxBytes    int // maximum JSON body size per request, unlimited when 0
+	Concurrency int // maximum number of requests in flight, defaults to 1
+}
+
+// BatchError reports the chunk of a batch insert or upsert that failed
+type BatchError struct {
+	Chunk  int   // index of the failed chunk
+	Offset int   // index in the input of the first row of the chunk
+	Rows   int   // number of rows in the chunk
+	Err    error // the *PostgrestError returned by PostgREST, or the request error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch chunk %d (rows %d-%d): %v", e.Chunk, e.Offset, e.Offset+e.Rows-1, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// InsertBatch inserts rows in chunks of at most BatchSize rows and MaxBytes
+// bytes of JSON, sending up to Concurrency requests at a time. Rows returned
+// with Returning "representation" are concatenated in input order and counts
+// are summed.
+//
+// Each chunk is its own transaction. The first failure stops the remaining
+// chunks and is returned as a *BatchError, together with a response holding
+// the results of the chunks that succeeded.
+func (q *QueryBuilder[T]) InsertBatch(ctx context.Context, rows []T, opts *InsertOptions, batch *BatchOptions) (*PostgrestResponse[[]T], error) {
+	if opts == nil {
+		opts = &InsertOptions{DefaultToNull: true}
+	}
+	return q.executeBatches(ctx, rows, batch, func(q *QueryBuilder[T], chunk batchChunk) *FilterBuilder[interface{}] {
+		chunkOpts := *opts
+		if chunkOpts.Columns == nil {
+			chunkOpts.Columns = chunk.columns
+		}
+		return q.Insert(chunk.body, &chunkOpts)
+	})
+}
+
+// UpsertBatch upserts rows in chunks like InsertBatch
+func (q *QueryBuilder[T]) UpsertBatch(ctx context.Context, rows []T, opts *UpsertOptions, batch *BatchOptions) (*PostgrestResponse[[]T], error) {
+	if opts == nil {
+		opts = &UpsertOptions{DefaultToNull: true}
+	}
+	return q.executeBatches(ctx, rows, batch, func(q *QueryBuilder[T], chunk batchChunk) *FilterBuilder[interface{}] {
+		chunkOpts := *opts
+		if chunkOpts.Columns == nil {
+			chunkOpts.Columns = chunk.columns
+		}
+		return q.Upsert(chunk.body, &chunkOpts)
+	})
+}
+
+// batchChunk is a chunk of rows ready to be sent as a request body
+type batchChunk struct {
+	offset int
+	rows   int
+	body   interface{}
+	// columns are those of the rows of an encoded body, which are found from
+	// the rows rather than by decoding it again
+	columns []string
+}
+
+func (q *QueryBuilder[T]) executeBatches(ctx context.Context, rows []T, opts *BatchOptions, build func(*QueryBuilder[T], batchChunk) *FilterBuilder[interface{}]) (*PostgrestResponse[[]T], error) {
+	if opts == nil {
+		opts = &BatchOptions{}
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	chunks, err := chunkRows(rows, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		failure *BatchError
+		results = make([]*PostgrestResponse[[]T], len(chunks))
+		slots   = make(chan struct{}, concurrency)
+	)
+
+	for i, chunk := range chunks {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		// Insert and Upsert set the columns parameter on the builder's URL,
+		// so every chunk gets its own copy
+		b := retype[[]T](build(q.clone(), chunk).Builder)
+
+		wg.Add(1)
+		go func(i int, chunk batchChunk) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			response, err := b.Execute(ctx)
+			if err == nil && response.Error != nil {
+				err = response.Error
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				results[i] = response
+				return
+			}
+			if failure == nil {
+				failure = &BatchError{Chunk: i, Offset: chunk.offset, Rows: chunk.rows, Err: err}
+				cancel()
+			}
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	merged := mergeBatchResults(results)
+	if failure != nil {
+		return merged, failure
+	}
+	if ctx.Err() != nil {
+		return merged, ctx.Err()
+	}
+	return merged, nil
+}
+
+// chunkRows splits rows into chunks of at most BatchSize rows and, when
+// MaxBytes is set, at most MaxBytes of JSON. A row larger than MaxBytes is
+// sent on its own.
+func chunkRows[T any](rows []T, opts *BatchOptions) ([]batchChunk, error) {
+	size := opts.BatchSize
+	if size <= 0 {
+		size = 1000
+	}
+
+	var chunks []batchChunk
+	if opts.MaxBytes <= 0 {
+		for offset := 0; offset < len(rows); offset += size {
+			end := min(offset+size, len(rows))
+			chunks = append(chunks, batchChunk{offset: offset, rows: end - offset, body: rows[offset:end]})
+		}
+		return chunks, nil
+	}
+
+	// Rows are marshaled once to measure them and sent as is
+	var current []json.RawMessage
+	offset, length := 0, 0
+	for i, row := range rows {
+		encoded, err := json.Marshal(row)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling row %d: %w", i, err)
+		}
+		// brackets or separating comma
+		added := len(encoded) + 1
+		if len(current) == 0 {
+			added++
+		}
+		if len(current) > 0 && (len(current) >= size || length+added > opts.MaxBytes) {
+			chunks = append(chunks, batchChunk{offset: offset, rows: len(current), body: current, columns: insertColumns(rows[offset:i])})
+			current, offset, length = nil, i, 0
+			added++
+		}
+		current = append(current, encoded)
+		length += added
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, batchChunk{offset: offset, rows: len(current), body: current, columns: insertColumns(rows[offset:])})
+	}
+	return chunks, nil
+}
+
+// mergeBatchResults concatenates the rows returned by the chunks that
+// succeeded and sums their counts
+func mergeBatchResults[T any](results []*PostgrestResponse[[]T]) *PostgrestResponse[[]T] {
+	merged := &PostgrestResponse[[]T]{Data: []T{}}
+	first := true
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		if first {
+			*merged = *result
+			merged.Data = []T{}
+			merged.Count = nil
+			first = false
+		}
+		merged.Data = append(merged.Data, result.Data...)
+		if result.Count != nil {
+			total := *result.Count
+			if merged.Count != nil {
+				total += *merged.Count
+			}
+			merged.Count = &total
+		}
+	}
+	return merged
+}
@@ -0,0 +1,131 @@
+package postgrest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type batchUser struct {
+	Username string `json:"username"`
+	Status   string `json:"status"`
+}
+
+func batchUsers(n int) []batchUser {
+	users := make([]batchUser, n)
+	for i := range users {
+		users[i] = batchUser{Username: fmt.Sprintf("user%02d", i), Status: "ONLINE"}
+	}
+	return users
+}
+
+// registerBatchInsert mocks inserts into users that echo the inserted rows,
+// rejecting any chunk containing failUsername. It returns the body sizes and
+// row counts of the requests.
+func registerBatchInsert(failUsername string) (*[]int, *[]int) {
+	var mu sync.Mutex
+	var sizes, counts []int
+	httpmock.RegisterRegexpResponder("POST", mockPath, func(req *http.Request) (*http.Response, error) {
+		var rows []batchUser
+		body := json.NewDecoder(req.Body)
+		if err := body.Decode(&rows); err != nil {
+			return nil, err
+		}
+		encoded, _ := json.Marshal(rows)
+
+		mu.Lock()
+		sizes = append(sizes, len(encoded))
+		counts = append(counts, len(rows))
+		mu.Unlock()
+
+		for _, row := range rows {
+			if row.Username == failUsername {
+				return httpmock.NewJsonResponse(409, map[string]interface{}{
+					"code":    "23505",
+					"message": "duplicate key value violates unique constraint \"users_pkey\"",
+				})
+			}
+		}
+		resp, _ := httpmock.NewJsonResponse(201, rows)
+		resp.Header.Set("Content-Range", fmt.Sprintf("*/%d", len(rows)))
+		return resp, nil
+	})
+	return &sizes, &counts
+}
+
+func TestQueryBuilder_InsertBatch(t *testing.T) {
+	c := createClient(t)
+	if !mockResponses {
+		t.Skip("requires mocked responses")
+	}
+
+	t.Run("SplitsByRows", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		_, counts := registerBatchInsert("")
+
+		users := batchUsers(25)
+		response, err := NewQueryBuilder[batchUser](c, "users").InsertBatch(context.Background(), users,
+			&InsertOptions{Count: "exact", Returning: "representation", DefaultToNull: true},
+			&BatchOptions{BatchSize: 10, Concurrency: 3})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []int{10, 10, 5}, *counts)
+		assert.Equal(t, users, response.Data)
+		if assert.NotNil(t, response.Count) {
+			assert.Equal(t, int64(25), *response.Count)
+		}
+		assert.Equal(t, 201, response.Status)
+	})
+
+	t.Run("SplitsByBytes", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		sizes, _ := registerBatchInsert("")
+
+		response, err := NewQueryBuilder[batchUser](c, "users").UpsertBatch(context.Background(), batchUsers(20),
+			&UpsertOptions{OnConflict: "username", Returning: "representation", DefaultToNull: true},
+			&BatchOptions{MaxBytes: 200})
+		assert.NoError(t, err)
+		assert.Len(t, response.Data, 20)
+		assert.Greater(t, len(*sizes), 1)
+		for _, size := range *sizes {
+			assert.LessOrEqual(t, size, 200)
+		}
+	})
+
+	t.Run("ReportsFailedChunk", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		_, counts := registerBatchInsert("user12")
+
+		response, err := NewQueryBuilder[batchUser](c, "users").InsertBatch(context.Background(), batchUsers(25),
+			&InsertOptions{Returning: "representation", DefaultToNull: true},
+			&BatchOptions{BatchSize: 5})
+
+		var batchErr *BatchError
+		if assert.True(t, errors.As(err, &batchErr)) {
+			assert.Equal(t, 2, batchErr.Chunk)
+			assert.Equal(t, 10, batchErr.Offset)
+			assert.Equal(t, 5, batchErr.Rows)
+		}
+		var pgErr *PostgrestError
+		if assert.True(t, errors.As(err, &pgErr)) {
+			assert.Equal(t, "23505", pgErr.Code)
+		}
+		assert.Len(t, *counts, 3)
+		assert.Len(t, response.Data, 10)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		response, err := NewQueryBuilder[batchUser](c, "users").InsertBatch(context.Background(), nil, nil, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, response.Data)
+	})
+}
@@ -1,14 +1,18 @@
 package postgrest
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"net/url"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -16,6 +20,12 @@ import (
 // Builder is the base builder for PostgREST queries
 // Similar to PostgrestBuilder in postgrest-js
 type Builder[T any] struct {
+	builderState
+}
+
+// builderState is the request state of a Builder, which doesn't depend on
+// the type its response is decoded as
+type builderState struct {
 	method             string
 	url                *url.URL
 	headers            http.Header
@@ -25,6 +35,16 @@ type Builder[T any] struct {
 	signal             context.Context
 	client             *Client
 	isMaybeSingle      bool
+	// err is an invalid argument recorded while building the query, returned
+	// when it is executed
+	err error
+	// inFilters are the In filters ExecuteChunked can split
+	inFilters []inFilter
+	// versioned marks a versioned update, which fails with ErrConflict when
+	// no row was updated
+	versioned bool
+	// allowUnfiltered lets an UPDATE or DELETE be sent without filters
+	allowUnfiltered bool
 }
 
 // NewBuilder creates a new Builder instance
@@ -33,7 +53,7 @@ func NewBuilder[T any](client *Client, method string, url *url.URL, opts *Builde
 		opts = &BuilderOptions{}
 	}
 
-	b := &Builder[T]{
+	b := &Builder[T]{builderState{
 		method:             method,
 		url:                url,
 		headers:            make(http.Header),
@@ -43,7 +63,7 @@ func NewBuilder[T any](client *Client, method string, url *url.URL, opts *Builde
 		signal:             opts.Signal,
 		client:             client,
 		isMaybeSingle:      opts.IsMaybeSingle,
-	}
+	}}
 
 	// Copy headers from client
 	if client != nil && client.Transport != nil {
@@ -84,19 +104,34 @@ func (b *Builder[T]) ThrowOnError() *Builder[T] {
 	return b
 }
 
+// setErr records the first invalid argument given to the builder
+func (b *Builder[T]) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
 // SetHeader sets an HTTP header for the request
 func (b *Builder[T]) SetHeader(name, value string) *Builder[T] {
 	b.headers.Set(name, value)
 	return b
 }
 
-// Execute executes the query and returns the response
-func (b *Builder[T]) Execute(ctx context.Context) (*PostgrestResponse[T], error) {
-	if ctx == nil {
-		ctx = context.Background()
+// newRequest prepares the HTTP request for the builder's query
+func (b *Builder[T]) newRequest(ctx context.Context) (req *http.Request, err error) {
+	// A streamed body is closed when the request can't be sent, so that the
+	// goroutine producing it stops
+	defer func() {
+		if closer, ok := b.body.(io.Closer); ok && err != nil {
+			closer.Close()
+		}
+	}()
+
+	if b.err != nil {
+		return nil, b.err
 	}
-	if b.signal != nil {
-		ctx = b.signal
+	if err := b.guardMutation(); err != nil {
+		return nil, err
 	}
 
 	// Set schema headers
@@ -108,14 +143,24 @@ func (b *Builder[T]) Execute(ctx context.Context) (*PostgrestResponse[T], error)
 		}
 	}
 
-	// Set Content-Type for non-GET/HEAD requests
-	if b.method != "GET" && b.method != "HEAD" {
+	// Set Content-Type for non-GET/HEAD requests, unless the body is in
+	// another format such as CSV
+	if b.method != "GET" && b.method != "HEAD" && b.headers.Get("Content-Type") == "" {
 		b.headers.Set("Content-Type", "application/json")
 	}
 
-	// Prepare request body
+	// Send all preferences as a single Prefer header, the last value set for
+	// a preference taking precedence
+	if prefs := parsePreferences(b.headers.Values("Prefer")); len(prefs) > 0 {
+		b.headers.Set("Prefer", prefs.String())
+	}
+
+	// Prepare request body. A reader is streamed as is, with chunked transfer
+	// encoding when its length is unknown.
 	var bodyReader io.Reader
-	if b.body != nil {
+	if reader, ok := b.body.(io.Reader); ok {
+		bodyReader = reader
+	} else if b.body != nil {
 		bodyBytes, err := json.Marshal(b.body)
 		if err != nil {
 			return nil, fmt.Errorf("error marshaling body: %w", err)
@@ -129,7 +174,7 @@ func (b *Builder[T]) Execute(ctx context.Context) (*PostgrestResponse[T], error)
 	}
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, b.method, b.url.String(), bodyReader)
+	req, err = http.NewRequestWithContext(ctx, b.method, b.url.String(), bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
@@ -140,18 +185,37 @@ func (b *Builder[T]) Execute(ctx context.Context) (*PostgrestResponse[T], error)
 			req.Header.Add(key, val)
 		}
 	}
+	b.applyDryRun(ctx, req)
+
+	return req, nil
+}
+
+// send executes the request. A transport failure is returned as a response
+// carrying a FetchError unless the builder throws on error.
+func (b *Builder[T]) send(ctx context.Context) (*http.Response, *PostgrestResponse[T], error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if b.signal != nil {
+		ctx = b.signal
+	}
+
+	req, err := b.newRequest(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Execute request
 	resp, err := b.client.session.Do(req)
 	if err != nil {
 		// Check if error is due to context cancellation
 		if ctx.Err() != nil {
-			return nil, ctx.Err()
+			return nil, nil, ctx.Err()
 		}
 		if b.shouldThrowOnError {
-			return nil, err
+			return nil, nil, err
 		}
-		return &PostgrestResponse[T]{
+		return nil, &PostgrestResponse[T]{
 			Error: NewPostgrestError(
 				fmt.Sprintf("FetchError: %s", err.Error()),
 				fmt.Sprintf("%v", err),
@@ -164,90 +228,87 @@ func (b *Builder[T]) Execute(ctx context.Context) (*PostgrestResponse[T], error)
 			StatusText: "",
 		}, nil
 	}
-	defer resp.Body.Close()
-
-	// Read response body
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response: %w", err)
-	}
-
-	// Parse response
-	response := &PostgrestResponse[T]{
-		Status:     resp.StatusCode,
-		StatusText: resp.Status,
-	}
+	return resp, nil, nil
+}
 
-	// Handle errors
-	if resp.StatusCode >= 400 {
-		var errorData map[string]interface{}
-		if err := json.Unmarshal(bodyBytes, &errorData); err != nil {
-			// Workaround for https://github.com/supabase/postgrest-js/issues/295
-			if resp.StatusCode == 404 && len(bodyBytes) == 0 {
-				response.Status = 204
-				response.StatusText = "No Content"
-				return response, nil
-			}
-			response.Error = NewPostgrestError(
-				string(bodyBytes),
-				"",
-				"",
-				"",
-			)
+// errorResponse fills response from the body of a PostgREST error response
+func (b *Builder[T]) errorResponse(response *PostgrestResponse[T], bodyBytes []byte) (*PostgrestResponse[T], error) {
+	var errorData map[string]interface{}
+	if err := json.Unmarshal(bodyBytes, &errorData); err != nil {
+		// Workaround for https://github.com/supabase/postgrest-js/issues/295
+		if response.Status == 404 && len(bodyBytes) == 0 {
+			response.Status = 204
+			response.StatusText = "No Content"
 			return response, nil
 		}
+		response.Error = NewPostgrestError(
+			string(bodyBytes),
+			"",
+			"",
+			"",
+		)
+		return response, nil
+	}
 
-		// Workaround for https://github.com/supabase/postgrest-js/issues/295
-		if resp.StatusCode == 404 && len(bodyBytes) > 0 {
-			var arr []interface{}
-			if err := json.Unmarshal(bodyBytes, &arr); err == nil {
-				response.Data = *new(T)
-				response.Status = 200
-				response.StatusText = "OK"
-				return response, nil
-			}
+	// Workaround for https://github.com/supabase/postgrest-js/issues/295
+	if response.Status == 404 && len(bodyBytes) > 0 {
+		var arr []interface{}
+		if err := json.Unmarshal(bodyBytes, &arr); err == nil {
+			response.Data = *new(T)
+			response.Status = 200
+			response.StatusText = "OK"
+			return response, nil
 		}
+	}
 
-		errorMsg := ""
-		if msg, ok := errorData["message"].(string); ok {
-			errorMsg = msg
-		}
+	response.Error = errorFromData(errorData)
 
-		errorDetails := ""
-		if details, ok := errorData["details"].(string); ok {
-			errorDetails = details
-		}
+	// Handle maybeSingle case
+	if b.isMaybeSingle && response.Error != nil && strings.Contains(response.Error.Details, "0 rows") {
+		response.Error = nil
+		response.Status = 200
+		response.StatusText = "OK"
+	}
 
-		errorHint := ""
-		if hint, ok := errorData["hint"].(string); ok {
-			errorHint = hint
-		}
+	// When Single() is used and there's an error, return the error
+	acceptHeader := b.headers.Get("Accept")
+	if acceptHeader == "application/vnd.pgrst.object+json" && response.Error != nil {
+		return nil, response.Error
+	}
 
-		errorCode := ""
-		if code, ok := errorData["code"].(string); ok {
-			errorCode = code
-		}
+	if b.shouldThrowOnError && response.Error != nil {
+		return nil, response.Error
+	}
 
-		response.Error = NewPostgrestError(errorMsg, errorDetails, errorHint, errorCode)
+	return response, nil
+}
 
-		// Handle maybeSingle case
-		if b.isMaybeSingle && response.Error != nil && strings.Contains(errorDetails, "0 rows") {
-			response.Error = nil
-			response.Status = 200
-			response.StatusText = "OK"
-		}
+// Execute executes the query and returns the response
+func (b *Builder[T]) Execute(ctx context.Context) (*PostgrestResponse[T], error) {
+	resp, failed, err := b.send(ctx)
+	if err != nil || failed != nil {
+		return failed, err
+	}
+	defer resp.Body.Close()
 
-		// When Single() is used and there's an error, return the error
-		acceptHeader := b.headers.Get("Accept")
-		if acceptHeader == "application/vnd.pgrst.object+json" && response.Error != nil {
-			return nil, response.Error
-		}
+	// Read response body
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
 
-		if b.shouldThrowOnError && response.Error != nil {
-			return nil, response.Error
-		}
+	// Parse response
+	response := &PostgrestResponse[T]{
+		Status:            resp.StatusCode,
+		StatusText:        resp.Status,
+		Header:            resp.Header,
+		PreferenceApplied: parsePreferences(resp.Header.Values("Preference-Applied")),
+		Location:          resp.Header.Get("Location"),
+	}
 
-		return response, nil
+	// Handle errors
+	if resp.StatusCode >= 400 {
+		return b.errorResponse(response, bodyBytes)
 	}
 
 	// Parse successful response
@@ -256,10 +317,18 @@ func (b *Builder[T]) Execute(ctx context.Context) (*PostgrestResponse[T], error)
 		if acceptHeader == "text/csv" || strings.Contains(acceptHeader, "application/vnd.pgrst.plan+text") {
 			// For CSV and plan text, try to unmarshal as string
 			strData := string(bodyBytes)
-			// Use type assertion to check if T is string
-			var zeroT T
-			if _, ok := any(zeroT).(string); ok {
-				response.Data = any(strData).(T)
+			if data, ok := any(strData).(T); ok {
+				// T is string or interface{}
+				response.Data = data
+			} else if acceptHeader == "text/csv" {
+				// Rows of other types are decoded from the CSV by column name
+				data := reflect.ValueOf(&response.Data).Elem()
+				if data.Kind() != reflect.Slice {
+					return nil, fmt.Errorf("cannot decode CSV into %v: rows must be structs", data.Type())
+				}
+				if err := decodeCSV(bytes.NewReader(bodyBytes), data); err != nil {
+					return nil, fmt.Errorf("error decoding CSV response: %w", err)
+				}
 			}
 		} else if len(bodyBytes) > 0 {
 			acceptHeader := b.headers.Get("Accept")
@@ -304,11 +373,11 @@ func (b *Builder[T]) Execute(ctx context.Context) (*PostgrestResponse[T], error)
 						// Unmarshal single item
 						itemBytes, err := json.Marshal(arr[0])
 						if err != nil {
-    						return nil, fmt.Errorf("error marshaling maybeSingle item: %w", err)
+							return nil, fmt.Errorf("error marshaling maybeSingle item: %w", err)
 						}
 
 						if err := json.Unmarshal(itemBytes, &response.Data); err != nil {
-    						return nil, fmt.Errorf("error unmarshaling maybeSingle item: %w", err)
+							return nil, fmt.Errorf("error unmarshaling maybeSingle item: %w", err)
 						}
 
 					} else {
@@ -338,28 +407,152 @@ func (b *Builder[T]) Execute(ctx context.Context) (*PostgrestResponse[T], error)
 				}
 			} else {
 				if err := json.Unmarshal(bodyBytes, &response.Data); err != nil {
-        			return nil, fmt.Errorf("error unmarshaling response: %w", err)
-    			}
+					return nil, fmt.Errorf("error unmarshaling response: %w", err)
+				}
 			}
 		}
 	}
 
-	// Parse count from Content-Range header
-	contentRange := resp.Header.Get("Content-Range")
+	response.Count = parseCount(resp.Header)
+	if err := b.checkConflict(response.Count); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ErrConflict is returned by a versioned update when no row was updated,
+// because the row was modified or deleted since its version was read
+var ErrConflict = errors.New("conflict: row was modified or deleted")
+
+// checkConflict returns ErrConflict when a versioned update updated no row
+func (b *Builder[T]) checkConflict(count *int64) error {
+	if b.versioned && count != nil && *count == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// parseCount returns the total from the Content-Range header, if any
+func parseCount(header http.Header) *int64 {
+	contentRange := header.Get("Content-Range")
 	if contentRange != "" {
 		parts := strings.Split(contentRange, "/")
 		if len(parts) > 1 && parts[1] != "*" {
 			if count, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
-				response.Count = &count
+				return &count
 			}
 		}
 	}
-
-	return response, nil
+	return nil
 }
 
-// ExecuteTo executes the query and unmarshals the result into the provided interface
+// ExecuteTo executes the query and unmarshals the result into the provided interface.
+// Plain JSON responses are decoded from the response body straight into to.
 func (b *Builder[T]) ExecuteTo(ctx context.Context, to interface{}) (*int64, error) {
+	accept := b.headers.Get("Accept")
+	if _, isString := to.(*string); accept == "text/csv" && !isString && b.method != "HEAD" {
+		return b.executeCSVTo(ctx, to)
+	}
+	if b.method == "HEAD" || b.isMaybeSingle || accept != "application/json" {
+		return b.executeToViaData(ctx, to)
+	}
+
+	resp, err := b.sendOK(ctx)
+	if err != nil || resp == nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	count := parseCount(resp.Header)
+	if err := b.checkConflict(count); err != nil {
+		return nil, err
+	}
+
+	// An empty body, e.g. from return=minimal, leaves to untouched
+	if err := json.NewDecoder(resp.Body).Decode(to); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("error unmarshaling to target: %w", err)
+	}
+
+	return count, nil
+}
+
+// executeCSVTo executes a CSV query and decodes the rows into to, a pointer
+// to a slice of structs
+func (b *Builder[T]) executeCSVTo(ctx context.Context, to interface{}) (*int64, error) {
+	target := reflect.ValueOf(to)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("cannot decode CSV into %T: target must be a pointer to a slice", to)
+	}
+
+	resp, err := b.sendOK(ctx)
+	if err != nil || resp == nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	rows := target.Elem()
+	rows.SetLen(0)
+	if err := decodeCSV(resp.Body, rows); err != nil {
+		return nil, fmt.Errorf("error decoding CSV response: %w", err)
+	}
+
+	return parseCount(resp.Header), nil
+}
+
+// ExecuteToWriter executes the query and copies the response body to w as it
+// is read, e.g. to save a CSV export to a file without holding it in memory.
+// It returns the count reported by PostgREST, if any.
+func (b *Builder[T]) ExecuteToWriter(ctx context.Context, w io.Writer) (*int64, error) {
+	resp, err := b.sendOK(ctx)
+	if err != nil || resp == nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+
+	return parseCount(resp.Header), nil
+}
+
+// sendOK executes the request and returns the response of a successful
+// request, whose body the caller must close. Error responses are returned as
+// errors, or as a nil response when Execute would report them as success.
+func (b *Builder[T]) sendOK(ctx context.Context) (*http.Response, error) {
+	resp, failed, err := b.send(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if failed != nil {
+		return nil, failed.Error
+	}
+	if resp.StatusCode < 400 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+	response, err := b.errorResponse(&PostgrestResponse[T]{
+		Status:     resp.StatusCode,
+		StatusText: resp.Status,
+	}, bodyBytes)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, response.Error
+	}
+	return nil, nil
+}
+
+// executeToViaData executes the query and converts the decoded response data
+// into to, for responses that need Execute's handling of Single and MaybeSingle
+func (b *Builder[T]) executeToViaData(ctx context.Context, to interface{}) (*int64, error) {
 	response, err := b.Execute(ctx)
 	if err != nil {
 		return nil, err
@@ -381,3 +574,228 @@ func (b *Builder[T]) ExecuteTo(ctx context.Context, to interface{}) (*int64, err
 
 	return response.Count, nil
 }
+
+// StreamRows executes the query and decodes the rows of the JSON array response
+// one at a time as they are read, so the body is never held in memory as a
+// whole. A single object response, as returned after Single, yields one row.
+func StreamRows[T any](ctx context.Context, b *Builder[[]T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		resp, failed, err := b.send(ctx)
+		if err == nil && failed != nil {
+			err = failed.Error
+		}
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			bodyBytes, _ := io.ReadAll(resp.Body)
+			yield(zero, errorFromBody(bodyBytes, resp.Status))
+			return
+		}
+
+		body := bufio.NewReader(resp.Body)
+		first, err := peekNonSpace(body)
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			yield(zero, fmt.Errorf("error reading response: %w", err))
+			return
+		}
+
+		dec := json.NewDecoder(body)
+		if first != '[' {
+			var row T
+			if err := dec.Decode(&row); err != nil {
+				yield(zero, fmt.Errorf("error unmarshaling response: %w", err))
+				return
+			}
+			yield(row, nil)
+			return
+		}
+
+		if _, err := dec.Token(); err != nil {
+			yield(zero, fmt.Errorf("error unmarshaling response: %w", err))
+			return
+		}
+		for dec.More() {
+			var row T
+			if err := dec.Decode(&row); err != nil {
+				yield(zero, fmt.Errorf("error unmarshaling row: %w", err))
+				return
+			}
+			if !yield(row, nil) {
+				return
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			yield(zero, fmt.Errorf("error unmarshaling response: %w", err))
+		}
+	}
+}
+
+// peekNonSpace discards leading JSON whitespace and returns the next byte
+// without consuming it
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		next, err := r.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		switch next[0] {
+		case ' ', '\t', '\n', '\r':
+			_, _ = r.ReadByte()
+		default:
+			return next[0], nil
+		}
+	}
+}
+
+// clone returns a copy of the builder whose URL and headers can be modified
+// without affecting the original
+func (b *Builder[T]) clone() *Builder[T] {
+	c := *b
+	u := *b.url
+	c.url = &u
+	c.headers = b.headers.Clone()
+	c.inFilters = slices.Clone(b.inFilters)
+	return &c
+}
+
+// retype returns a builder for the same request that decodes its response
+// data as U, e.g. the rows returned by a mutation
+func retype[U, T any](b *Builder[T]) *Builder[U] {
+	return &Builder[U]{b.builderState}
+}
+
+// plannedCountExactBelow is the planned count under which Count falls back to
+// an exact count. Planner estimates are least reliable for small tables, where
+// an exact count is also cheap.
+const plannedCountExactBelow = 1000
+
+// errCountUnavailable is returned by headCount when PostgREST does not report
+// a total in the Content-Range header
+var errCountUnavailable = errors.New("count unavailable")
+
+// Count returns the number of rows matched by the query using a HEAD request.
+// mode is "exact", "planned" or "estimated" and defaults to "exact". A
+// "planned" count that is unavailable or lower than 1000 rows is replaced by
+// an exact count. Limit and Range do not affect the result.
+func (b *Builder[T]) Count(ctx context.Context, mode string) (int64, error) {
+	switch mode {
+	case "":
+		mode = "exact"
+	case "exact", "planned", "estimated":
+	default:
+		return 0, fmt.Errorf("invalid count mode %q", mode)
+	}
+
+	count, err := b.headCount(ctx, mode)
+	if mode != "planned" {
+		return count, err
+	}
+	if err == nil && count >= plannedCountExactBelow {
+		return count, nil
+	}
+	if err != nil && !errors.Is(err, errCountUnavailable) {
+		return 0, err
+	}
+	return b.headCount(ctx, "exact")
+}
+
+// Exists reports whether the query matches at least one row. It issues a HEAD
+// request limited to a single row without counting, so PostgREST can stop at
+// the first match.
+func (b *Builder[T]) Exists(ctx context.Context) (bool, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if b.signal != nil {
+		ctx = b.signal
+	}
+
+	head := b.clone()
+	head.method = "HEAD"
+	head.headers.Set("Accept", "application/json")
+	setPreference(head.headers, "count", "")
+
+	query := head.url.Query()
+	query.Del("offset")
+	query.Set("limit", "1")
+	head.url.RawQuery = query.Encode()
+
+	req, err := head.newRequest(ctx)
+	if err != nil {
+		return false, err
+	}
+	resp, err := head.client.session.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return false, errorFromBody(body, resp.Status)
+	}
+
+	// Content-Range is "0-0/*" when a row matched and "*/*" otherwise
+	return !strings.HasPrefix(resp.Header.Get("Content-Range"), "*"), nil
+}
+
+// headCount issues a HEAD request for the builder's query and returns the row
+// count reported by PostgREST for the given count mode
+func (b *Builder[T]) headCount(ctx context.Context, mode string) (int64, error) {
+	head := b.clone()
+	head.method = "HEAD"
+	head.isMaybeSingle = false
+	head.headers.Set("Accept", "application/json")
+	setPreference(head.headers, "count", mode)
+
+	query := head.url.Query()
+	query.Del("offset")
+	query.Del("limit")
+	head.url.RawQuery = query.Encode()
+
+	response, err := head.Execute(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if response.Error != nil {
+		return 0, response.Error
+	}
+	if response.Count == nil {
+		return 0, fmt.Errorf("%w: no %s count returned for %s", errCountUnavailable, mode, head.url.Path)
+	}
+	return *response.Count, nil
+}
+
+// setPreference replaces the named preference in the Prefer header with
+// name=value, or removes it when value is empty
+func setPreference(headers http.Header, name, value string) {
+	var kept []string
+	for _, v := range headers.Values("Prefer") {
+		for _, pref := range strings.Split(v, ",") {
+			pref = strings.TrimSpace(pref)
+			if pref == "" || strings.SplitN(pref, "=", 2)[0] == name {
+				continue
+			}
+			kept = append(kept, pref)
+		}
+	}
+
+	headers.Del("Prefer")
+	for _, pref := range kept {
+		headers.Add("Prefer", pref)
+	}
+	if value != "" {
+		headers.Add("Prefer", name+"="+value)
+	}
+}
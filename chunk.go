@@ -0,0 +1,230 @@
+package postgrest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// inFilter is an In filter as it was added to the query, kept so that
+// ExecuteChunked can split its values
+type inFilter struct {
+	column string
+	values []string
+}
+
+// ChunkOptions contains options for ExecuteChunked
+type ChunkOptions struct {
+	MaxValues    int // in list values per request, defaults to 500
+	MaxURLLength int // maximum request URL length in bytes, defaults to 8000
+	Concurrency  int // maximum number of requests in flight, defaults to 4
+}
+
+// ExecuteChunked executes a query whose In filter may be too large for a
+// single request URL. The values of the largest In filter are deduplicated
+// and split into chunks bounded by MaxValues and MaxURLLength, and one request
+// per chunk is sent with at most Concurrency requests in flight. The first
+// error cancels the outstanding requests and is returned.
+//
+// Rows are merged in chunk order and counts requested with the count option
+// are summed. Order, Limit and Range would only apply to each chunk, so a
+// query using them that needs more than one request returns an error rather
+// than rows that are neither ordered nor limited. Queries that fit in a single
+// request are executed as is.
+func (b *Builder[T]) ExecuteChunked(ctx context.Context, opts *ChunkOptions) (*PostgrestResponse[T], error) {
+	if opts == nil {
+		opts = &ChunkOptions{}
+	}
+	maxValues := opts.MaxValues
+	if maxValues <= 0 {
+		maxValues = 500
+	}
+	maxURLLength := opts.MaxURLLength
+	if maxURLLength <= 0 {
+		maxURLLength = 8000
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	filter, ok := b.chunkableInFilter()
+	if !ok {
+		return b.Execute(ctx)
+	}
+	chunks := b.chunkInValues(filter, maxValues, maxURLLength)
+	if len(chunks) <= 1 {
+		return b.Execute(ctx)
+	}
+
+	if b.method != "GET" && b.method != "HEAD" {
+		return nil, fmt.Errorf("chunked execution requires a select query, got %s", b.method)
+	}
+	dataType := reflect.TypeFor[T]()
+	if b.method == "GET" && dataType.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("chunked execution requires a slice result, got %s", dataType)
+	}
+
+	query := b.url.Query()
+	for _, key := range []string{"order", "limit", "offset"} {
+		if query.Has(key) {
+			return nil, fmt.Errorf("chunked execution can't apply %s to rows split across %d requests", key, len(chunks))
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg          sync.WaitGroup
+		mu          sync.Mutex
+		failed      *PostgrestResponse[T]
+		failure     error
+		results     = make([]*PostgrestResponse[T], len(chunks))
+		slots       = make(chan struct{}, concurrency)
+		filterValue = formatInList(filter.values)
+	)
+
+	for i, chunk := range chunks {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		c := b.clone()
+		chunkQuery := cloneValues(query)
+		values := chunkQuery[filter.column]
+		values[slices.Index(values, filterValue)] = formatInList(chunk)
+		c.url.RawQuery = chunkQuery.Encode()
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			response, err := c.Execute(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil && response.Error == nil {
+				results[i] = response
+				return
+			}
+			if failed == nil && failure == nil {
+				failed, failure = response, err
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if failed != nil || failure != nil {
+		return failed, failure
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return mergeChunks(results), nil
+}
+
+// chunkableInFilter returns the In filter of the query with the most values
+func (b *Builder[T]) chunkableInFilter() (inFilter, bool) {
+	query := b.url.Query()
+	var best inFilter
+	found := false
+	for _, f := range b.inFilters {
+		if !slices.Contains(query[f.column], formatInList(f.values)) {
+			continue
+		}
+		if !found || len(f.values) > len(best.values) {
+			best, found = f, true
+		}
+	}
+	return best, found
+}
+
+// chunkInValues splits the deduplicated values of filter into chunks that
+// keep the request URL within maxURLLength. A value that doesn't fit on its
+// own still gets a chunk of its own.
+func (b *Builder[T]) chunkInValues(filter inFilter, maxValues, maxURLLength int) [][]string {
+	query := b.url.Query()
+	values := query[filter.column]
+	i := slices.Index(values, formatInList(filter.values))
+	query[filter.column] = slices.Delete(slices.Clone(values), i, i+1)
+	base := *b.url
+	base.RawQuery = query.Encode()
+	// the rest of the URL, "&column=" and the escaped "in.()"
+	overhead := len(base.String()) + len(url.QueryEscape(filter.column)) + 2 + len(url.QueryEscape("in.()"))
+	separator := len(url.QueryEscape(","))
+
+	var chunks [][]string
+	var current []string
+	length := overhead
+	seen := make(map[string]bool, len(filter.values))
+	for _, v := range filter.values {
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+
+		size := len(url.QueryEscape(v))
+		if len(current) > 0 {
+			size += separator
+		}
+		if len(current) > 0 && (len(current) >= maxValues || length+size > maxURLLength) {
+			chunks = append(chunks, current)
+			current, length = nil, overhead
+			size -= separator
+		}
+		current = append(current, v)
+		length += size
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// mergeChunks concatenates the rows of the chunk responses and sums their
+// counts
+func mergeChunks[T any](results []*PostgrestResponse[T]) *PostgrestResponse[T] {
+	merged := *results[0]
+	merged.Count = nil
+
+	for _, result := range results {
+		if result.Count != nil {
+			total := *result.Count
+			if merged.Count != nil {
+				total += *merged.Count
+			}
+			merged.Count = &total
+		}
+	}
+
+	data := reflect.ValueOf(&merged.Data).Elem()
+	if data.Kind() == reflect.Slice {
+		rows := reflect.MakeSlice(data.Type(), 0, 0)
+		for _, result := range results {
+			rows = reflect.AppendSlice(rows, reflect.ValueOf(result.Data))
+		}
+		data.Set(rows)
+	}
+	return &merged
+}
+
+// cloneValues returns a deep copy of query
+func cloneValues(query url.Values) url.Values {
+	c := make(url.Values, len(query))
+	for key, values := range query {
+		c[key] = slices.Clone(values)
+	}
+	return c
+}
@@ -0,0 +1,214 @@
+package postgrest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// chunkUsers are the users of the test schema, in table order
+var chunkUsers = []map[string]interface{}{
+	{"username": "supabot", "status": "ONLINE"},
+	{"username": "kiwicopple", "status": "OFFLINE"},
+	{"username": "awailas", "status": "ONLINE"},
+	{"username": "acupofjose", "status": "OFFLINE"},
+	{"username": "dragarcia", "status": "ONLINE"},
+}
+
+// registerInLookup mocks the users table for queries with an in filter on
+// username and an eq filter on status, failing for a request that includes
+// failName
+func registerInLookup(t *testing.T, failName string) *atomic.Int32 {
+	var inFlight, maxInFlight atomic.Int32
+	httpmock.RegisterRegexpResponder("GET", mockPath, func(req *http.Request) (*http.Response, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+
+		query := req.URL.Query()
+		assert.Equal(t, "eq.ONLINE", query.Get("status"))
+		names := strings.Split(strings.TrimSuffix(strings.TrimPrefix(query.Get("username"), "in.("), ")"), ",")
+		if slices.Contains(names, failName) {
+			return httpmock.NewJsonResponse(400, map[string]interface{}{"message": "chunk failed", "code": "22P02"})
+		}
+		rows := []map[string]interface{}{}
+		for _, user := range chunkUsers {
+			if user["status"] == "ONLINE" && slices.Contains(names, user["username"].(string)) {
+				rows = append(rows, map[string]interface{}{"username": user["username"]})
+			}
+		}
+		if strings.HasPrefix(query.Get("order"), "username") {
+			slices.SortFunc(rows, func(a, b map[string]interface{}) int {
+				return strings.Compare(a["username"].(string), b["username"].(string))
+			})
+		}
+		if limit := query.Get("limit"); limit != "" {
+			n, _ := strconv.Atoi(limit)
+			rows = rows[:min(n, len(rows))]
+		}
+
+		resp, _ := httpmock.NewJsonResponse(200, rows)
+		if strings.Contains(req.Header.Get("Prefer"), "count=exact") {
+			if len(rows) == 0 {
+				resp.Header.Set("Content-Range", "*/0")
+			} else {
+				resp.Header.Set("Content-Range", fmt.Sprintf("0-%d/%d", len(rows)-1, len(rows)))
+			}
+		}
+		return resp, nil
+	})
+	return &maxInFlight
+}
+
+// nameValues returns n usernames, of which those in place are set to the
+// given existing users
+func nameValues(n int, place map[int]string) []interface{} {
+	names := make([]interface{}, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("nobody%d", i)
+		if name, ok := place[i]; ok {
+			names[i] = name
+		}
+	}
+	return names
+}
+
+// usernames returns the username of each row
+func usernames(rows []map[string]interface{}) []interface{} {
+	names := make([]interface{}, len(rows))
+	for i, row := range rows {
+		names[i] = row["username"]
+	}
+	return names
+}
+
+func TestBuilder_ExecuteChunked(t *testing.T) {
+	c := createClient(t)
+	requests := recordRequests(c)
+	maxInFlight := new(atomic.Int32)
+	lookup := func(t *testing.T, failName string) {
+		requests.prefer()
+		if mockResponses {
+			httpmock.Activate()
+			t.Cleanup(httpmock.DeactivateAndReset)
+			maxInFlight = registerInLookup(t, failName)
+		}
+	}
+
+	t.Run("SplitsAndMerges", func(t *testing.T) {
+		lookup(t, "")
+
+		names := append(nameValues(24, map[int]string{3: "supabot", 12: "awailas", 15: "kiwicopple", 21: "dragarcia"}), "supabot")
+		response, err := c.From("users").
+			Select("username", &SelectOptions{Count: "exact"}).
+			In("username", names).
+			Eq("status", "ONLINE").
+			ExecuteChunked(context.Background(), &ChunkOptions{MaxValues: 10, Concurrency: 2})
+		assert.NoError(t, err)
+
+		assert.Equal(t, []interface{}{"supabot", "awailas", "dragarcia"}, usernames(response.Data))
+		if assert.NotNil(t, response.Count) {
+			assert.Equal(t, int64(3), *response.Count)
+		}
+		assert.Len(t, requests.prefer(), 3)
+		assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+	})
+
+	t.Run("URLLengthBudget", func(t *testing.T) {
+		lookup(t, "")
+
+		_, err := c.From("users").
+			Select("username", nil).
+			In("username", nameValues(300, nil)).
+			Eq("status", "ONLINE").
+			ExecuteChunked(context.Background(), &ChunkOptions{MaxURLLength: 400, Concurrency: 1})
+		assert.NoError(t, err)
+		assert.Greater(t, len(requests.requests), 1)
+		for _, req := range requests.requests {
+			assert.LessOrEqual(t, len(req.URL.String()), 400)
+		}
+	})
+
+	t.Run("RejectsOrderAndLimit", func(t *testing.T) {
+		lookup(t, "")
+
+		_, err := c.From("users").
+			Select("username", nil).
+			In("username", nameValues(30, nil)).
+			Eq("status", "ONLINE").
+			Range(5, 16, nil).
+			ExecuteChunked(context.Background(), &ChunkOptions{MaxValues: 10})
+		assert.EqualError(t, err, "chunked execution can't apply limit to rows split across 3 requests")
+
+		_, err = c.From("users").
+			Select("username", nil).
+			In("username", nameValues(30, nil)).
+			Eq("status", "ONLINE").
+			Order("username", nil).
+			ExecuteChunked(context.Background(), &ChunkOptions{MaxValues: 10})
+		assert.EqualError(t, err, "chunked execution can't apply order to rows split across 3 requests")
+		assert.Empty(t, requests.prefer())
+
+		response, err := c.From("users").
+			Select("username", nil).
+			In("username", nameValues(5, map[int]string{0: "supabot", 2: "dragarcia", 4: "awailas"})).
+			Eq("status", "ONLINE").
+			Order("username", nil).
+			Limit(2, nil).
+			ExecuteChunked(context.Background(), &ChunkOptions{MaxValues: 10})
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{"awailas", "dragarcia"}, usernames(response.Data))
+	})
+
+	t.Run("FirstErrorIsReturned", func(t *testing.T) {
+		if !mockResponses {
+			t.Skip("requires a mocked chunk failure")
+		}
+		lookup(t, "nobody15")
+
+		response, err := c.From("users").
+			Select("username", nil).
+			In("username", nameValues(30, nil)).
+			Eq("status", "ONLINE").
+			ExecuteChunked(context.Background(), &ChunkOptions{MaxValues: 10, Concurrency: 1})
+		assert.NoError(t, err)
+		if assert.NotNil(t, response.Error) {
+			assert.Equal(t, "chunk failed", response.Error.Message)
+		}
+		assert.Len(t, requests.prefer(), 2)
+	})
+
+	t.Run("SmallListIsSentAsIs", func(t *testing.T) {
+		lookup(t, "")
+
+		response, err := c.From("users").
+			Select("username", nil).
+			In("username", []interface{}{"supabot", "awailas"}).
+			Eq("status", "ONLINE").
+			ExecuteChunked(context.Background(), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{"supabot", "awailas"}, usernames(response.Data))
+		assert.Len(t, requests.prefer(), 1)
+	})
+
+	t.Run("RejectsMutations", func(t *testing.T) {
+		_, err := c.From("users").
+			Delete(nil).
+			In("username", nameValues(20, nil)).
+			ExecuteChunked(context.Background(), &ChunkOptions{MaxValues: 5})
+		assert.EqualError(t, err, "chunked execution requires a select query, got DELETE")
+	})
+}
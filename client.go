@@ -23,6 +23,12 @@ type Client struct {
 	session     *http.Client
 	Transport   *transport
 	schemaName  string
+	// allowUnfiltered lets UPDATE and DELETE requests without filters be sent
+	allowUnfiltered bool
+	// maxAffected is the max-affected ceiling of UPDATE and DELETE requests
+	maxAffected int
+	// dryRun rolls back every mutation and RPC
+	dryRun bool
 }
 
 // NewClientWithError constructs a new client given a URL to a Postgrest instance.
@@ -126,9 +132,12 @@ func (c *Client) ChangeSchema(schema string) *Client {
 // Schema selects a schema to query or perform an function (rpc) call
 func (c *Client) Schema(schema string) *Client {
 	newClient := &Client{
-		session:    c.session,
-		Transport:  c.Transport,
-		schemaName: schema,
+		session:         c.session,
+		Transport:       c.Transport,
+		schemaName:      schema,
+		allowUnfiltered: c.allowUnfiltered,
+		maxAffected:     c.maxAffected,
+		dryRun:          c.dryRun,
 	}
 
 	// Update schema headers
@@ -205,7 +214,7 @@ func (c *Client) Rpc(fn string, args interface{}, opts *RpcOptions) *FilterBuild
 	}
 
 	if opts.Count != "" && (opts.Count == "exact" || opts.Count == "planned" || opts.Count == "estimated") {
-		headers.Add("Prefer", fmt.Sprintf("count=%s", opts.Count))
+		setPreference(headers, "count", opts.Count)
 	}
 
 	builder := NewBuilder[interface{}](c, method, rpcURL, &BuilderOptions{
@@ -257,8 +266,13 @@ func (t *transport) SetHeaders(headers map[string]string) {
 }
 
 func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Headers set by the request, such as the Content-Type of a CSV body,
+	// take precedence over those of the client
 	t.mu.RLock()
 	for headerName, values := range t.header {
+		if _, ok := req.Header[headerName]; ok {
+			continue
+		}
 		for _, val := range values {
 			req.Header.Add(headerName, val)
 		}
@@ -0,0 +1,363 @@
+package postgrest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// columnField is a struct field stored in a column
+type columnField struct {
+	name  string
+	index []int
+}
+
+// jsonTags are the struct tags naming JSON columns
+var jsonTags = []string{"json"}
+
+var jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+
+// structColumns returns the columns of a struct type in field order, named by
+// the first of tags present or else by the field name, and flattening
+// embedded structs like encoding/json. Fields promoted from unexported
+// embedded structs are only included with promoteUnexported, as their values
+// can't be read through reflection.
+func structColumns(typ reflect.Type, tags []string, promoteUnexported bool) []columnField {
+	var fields []columnField
+	seen := make(map[string]bool)
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() || len(field.Index) > 1 && !embeddedChainPromoted(typ, field.Index, tags, promoteUnexported) {
+			continue
+		}
+		name, tagged := columnName(field, tags)
+		if name == "-" || seen[name] {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && !tagged && fieldType.Kind() == reflect.Struct {
+			// its fields are visited on their own
+			continue
+		}
+		seen[name] = true
+		fields = append(fields, columnField{name: name, index: field.Index})
+	}
+	return fields
+}
+
+// embeddedChainPromoted reports whether the embedded structs a promoted field
+// comes from are all flattened into the columns
+func embeddedChainPromoted(typ reflect.Type, index []int, tags []string, promoteUnexported bool) bool {
+	for i := 1; i < len(index); i++ {
+		field := typ.FieldByIndex(index[:i])
+		if name, tagged := columnName(field, tags); name == "-" || tagged {
+			return false
+		}
+		if !field.IsExported() && !promoteUnexported {
+			return false
+		}
+	}
+	return true
+}
+
+// columnName returns the column name of a field and whether it was set by a
+// tag
+func columnName(field reflect.StructField, tags []string) (string, bool) {
+	for _, key := range tags {
+		if tag, ok := field.Tag.Lookup(key); ok {
+			name, _, _ := strings.Cut(tag, ",")
+			if name != "" {
+				return name, true
+			}
+		}
+	}
+	return field.Name, false
+}
+
+// setColumns sets the columns parameter of an insert or upsert to columns,
+// or else to the columns of values
+func (q *QueryBuilder[T]) setColumns(values interface{}, columns []string) {
+	if columns == nil {
+		columns = insertColumns(values)
+	}
+	if len(columns) == 0 {
+		return
+	}
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = fmt.Sprintf(`"%s"`, col)
+	}
+	query := q.url.Query()
+	query.Set("columns", strings.Join(quoted, ","))
+	q.url.RawQuery = query.Encode()
+}
+
+// insertColumns returns the columns of the rows in values, a row or a slice of
+// rows: the keys each row is encoded with as JSON, in the order they are
+// first seen. Struct fields are listed in order, leaving out those omitted by
+// omitempty or omitzero so that they get their default, and map keys are
+// sorted. It returns nil for readers and for values whose columns can't be
+// determined.
+func insertColumns(values interface{}) []string {
+	if _, ok := values.(io.Reader); ok {
+		return nil
+	}
+	v := reflect.ValueOf(values)
+	if !v.IsValid() {
+		return nil
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	byType := make(map[reflect.Type][]omittableField)
+	add := func(row reflect.Value) bool {
+		names, ok := rowColumns(row, byType)
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				columns = append(columns, name)
+			}
+		}
+		return ok
+	}
+
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type() != reflect.TypeFor[json.RawMessage]() {
+		for i := 0; i < v.Len(); i++ {
+			if !add(v.Index(i)) {
+				return nil
+			}
+		}
+	} else if !add(v) {
+		return nil
+	}
+	return columns
+}
+
+// omittableField is a struct column with the omitempty and omitzero options
+// of its json tag
+type omittableField struct {
+	columnField
+	omitEmpty, omitZero bool
+}
+
+// rowColumns returns the columns of a row, caching the fields of struct
+// types in byType. Only rows implementing json.Marshaler are encoded to find
+// their keys. It reports false when the row is not an object.
+func rowColumns(row reflect.Value, byType map[reflect.Type][]omittableField) ([]string, bool) {
+	for row.Kind() == reflect.Pointer || row.Kind() == reflect.Interface {
+		if row.IsNil() {
+			return nil, true
+		}
+		row = row.Elem()
+	}
+
+	if raw, ok := row.Interface().(json.RawMessage); ok {
+		return objectKeys(raw)
+	}
+	if row.Type().Implements(jsonMarshalerType) || reflect.PointerTo(row.Type()).Implements(jsonMarshalerType) {
+		encoded, err := json.Marshal(row.Interface())
+		if err != nil {
+			return nil, false
+		}
+		return objectKeys(encoded)
+	}
+
+	switch row.Kind() {
+	case reflect.Map:
+		if row.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		names := make([]string, 0, row.Len())
+		for _, key := range row.MapKeys() {
+			names = append(names, key.String())
+		}
+		slices.Sort(names)
+		return names, true
+	case reflect.Struct:
+		fields, ok := byType[row.Type()]
+		if !ok {
+			for _, field := range structColumns(row.Type(), jsonTags, true) {
+				options := row.Type().FieldByIndex(field.index).Tag.Get("json")
+				fields = append(fields, omittableField{
+					columnField: field,
+					omitEmpty:   hasJSONOption(options, "omitempty"),
+					omitZero:    hasJSONOption(options, "omitzero"),
+				})
+			}
+			byType[row.Type()] = fields
+		}
+		names := make([]string, 0, len(fields))
+		for _, field := range fields {
+			value, err := row.FieldByIndexErr(field.index)
+			if err != nil {
+				// promoted from a nil embedded pointer
+				continue
+			}
+			if field.omitEmpty && isEmptyJSONValue(value) || field.omitZero && isZeroJSONValue(value) {
+				continue
+			}
+			names = append(names, field.name)
+		}
+		return names, true
+	}
+	return nil, false
+}
+
+// hasJSONOption reports whether a json tag lists option after the name
+func hasJSONOption(tag, option string) bool {
+	_, options, _ := strings.Cut(tag, ",")
+	return slices.Contains(strings.Split(options, ","), option)
+}
+
+// isEmptyJSONValue reports whether encoding/json omits v with omitempty
+func isEmptyJSONValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// isZeroJSONValue reports whether encoding/json omits v with omitzero: with
+// its IsZero method when it has one, or else when it is the zero value
+func isZeroJSONValue(v reflect.Value) bool {
+	if v.Kind() == reflect.Pointer && v.IsNil() || !v.CanInterface() {
+		return v.IsZero()
+	}
+	if z, ok := v.Interface().(zeroer); ok {
+		return z.IsZero()
+	}
+	if reflect.PointerTo(v.Type()).Implements(zeroerType) {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		return p.Interface().(zeroer).IsZero()
+	}
+	return v.IsZero()
+}
+
+// zeroer is implemented by types that report their own zero value for
+// omitzero, such as time.Time
+type zeroer interface {
+	IsZero() bool
+}
+
+var zeroerType = reflect.TypeFor[zeroer]()
+
+// objectKeys returns the keys of a JSON object in the order they appear
+func objectKeys(data []byte) ([]string, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, false
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, false
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, false
+		}
+		if name := token.(string); !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names, true
+}
+
+// hasTagOption reports whether the postgrest tag of a field lists option,
+// e.g. `postgrest:"pk"`
+func hasTagOption(field reflect.StructField, option string) bool {
+	return slices.Contains(strings.Split(field.Tag.Get("postgrest"), ","), option)
+}
+
+// primaryKeyFilters returns the eq filter values of the primary key columns
+// of row, a struct or a map with string keys. The columns default to the
+// struct fields tagged `postgrest:"pk"`.
+func primaryKeyFilters(row interface{}, columns []string) ([]string, []string, error) {
+	v := reflect.ValueOf(row)
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil, nil, fmt.Errorf("primary key of nil row")
+		}
+		v = v.Elem()
+	}
+
+	var values []string
+	switch {
+	case v.Kind() == reflect.Struct:
+		fields := structColumns(v.Type(), jsonTags, false)
+		if columns == nil {
+			for _, field := range fields {
+				if hasTagOption(v.Type().FieldByIndex(field.index), "pk") {
+					columns = append(columns, field.name)
+				}
+			}
+		}
+		for _, column := range columns {
+			i := slices.IndexFunc(fields, func(field columnField) bool { return field.name == column })
+			if i < 0 {
+				return nil, nil, fmt.Errorf("primary key column %s is not a field of %s", column, v.Type())
+			}
+			value, err := v.FieldByIndexErr(fields[i].index)
+			if err != nil {
+				return nil, nil, fmt.Errorf("primary key column %s is nil", column)
+			}
+			formatted, err := formatKeyValue(column, value)
+			if err != nil {
+				return nil, nil, err
+			}
+			values = append(values, formatted)
+		}
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		for _, column := range columns {
+			value := v.MapIndex(reflect.ValueOf(column).Convert(v.Type().Key()))
+			if !value.IsValid() {
+				return nil, nil, fmt.Errorf("primary key column %s is missing", column)
+			}
+			formatted, err := formatKeyValue(column, value)
+			if err != nil {
+				return nil, nil, err
+			}
+			values = append(values, formatted)
+		}
+	default:
+		return nil, nil, fmt.Errorf("cannot find the primary key of %T: rows must be structs or maps", row)
+	}
+
+	if len(columns) == 0 {
+		return nil, nil, fmt.Errorf("no primary key columns for %T", row)
+	}
+	return columns, values, nil
+}
+
+// formatKeyValue formats a primary key value for an eq filter
+func formatKeyValue(column string, value reflect.Value) (string, error) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return "", fmt.Errorf("primary key column %s is nil", column)
+		}
+		value = value.Elem()
+	}
+	return formatTextValue(value.Interface()), nil
+}
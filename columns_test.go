@@ -0,0 +1,120 @@
+package postgrest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type columnsBase struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type columnsUser struct {
+	Username string          `json:"username"`
+	Status   *string         `json:"status,omitempty"`
+	Profile  json.RawMessage `json:"profile"`
+	Age      int
+	Internal string `json:"-"`
+	columnsBase
+	hidden bool
+}
+
+type columnsRow struct {
+	ID   int64  `json:"id,omitempty"`
+	Name string `json:"name"`
+	Note string `json:"note,omitzero"`
+}
+
+type columnsEvent struct {
+	Name string    `json:"name"`
+	At   time.Time `json:"at,omitzero"`
+	Tags []string  `json:"tags,omitempty"`
+	columnsRow
+	*columnsBase
+}
+
+type columnsMarshaler struct{}
+
+func (columnsMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{"z":1,"a":2}`), nil
+}
+
+func TestInsertColumns(t *testing.T) {
+	tests := []struct {
+		name   string
+		values interface{}
+		want   []string
+	}{
+		{"Struct", columnsUser{}, []string{"username", "profile", "Age", "created_at"}},
+		{"StructPointers", []*columnsUser{nil, {}}, []string{"username", "profile", "Age", "created_at"}},
+		{"OmitEmpty", columnsRow{Name: "x"}, []string{"name"}},
+		{"OmitEmptyRows", []columnsRow{{Name: "x"}, {ID: 1, Note: "n"}}, []string{"name", "id", "note"}},
+		{"OmitZero", columnsEvent{Name: "x", Tags: []string{}}, []string{"name"}},
+		{"OmitZeroSet", []columnsEvent{{At: time.Unix(0, 0), columnsRow: columnsRow{ID: 1}, columnsBase: &columnsBase{}}}, []string{"name", "at", "id", "created_at"}},
+		{"Map", map[string]interface{}{"username": "a", "status": "b", "age": 1}, []string{"age", "status", "username"}},
+		{"Maps", []map[string]interface{}{{"username": "a"}, {"status": "b", "age": 1}}, []string{"username", "age", "status"}},
+		{"Mixed", []interface{}{map[string]int{"b": 1}, columnsBase{}}, []string{"b", "created_at"}},
+		{"RawMessages", []json.RawMessage{json.RawMessage(`{"b":1,"a":2}`)}, []string{"b", "a"}},
+		{"Marshaler", columnsMarshaler{}, []string{"z", "a"}},
+		{"Reader", strings.NewReader(`[{"a":1}]`), nil},
+		{"Scalars", []int{1, 2}, nil},
+		{"Nil", nil, nil},
+		{"Empty", []columnsUser{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, insertColumns(tt.values))
+		})
+	}
+}
+
+func TestQueryBuilder_InsertColumns(t *testing.T) {
+	c := createClient(t)
+	if !mockResponses {
+		t.Skip("requires mocked responses")
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var columns []string
+	httpmock.RegisterRegexpResponder("POST", mockPath, func(req *http.Request) (*http.Response, error) {
+		columns = append(columns, req.URL.Query().Get("columns"))
+		return httpmock.NewStringResponse(201, ""), nil
+	})
+
+	online := "ONLINE"
+	user := columnsUser{Username: "kiwicopple", Status: &online}
+	_, err := c.From("users").Insert(user, nil).Execute(context.Background())
+	assert.NoError(t, err)
+	_, err = c.From("users").Insert(user, &InsertOptions{Columns: []string{"username"}}).Execute(context.Background())
+	assert.NoError(t, err)
+	_, err = c.From("users").Upsert([]columnsUser{user}, &UpsertOptions{Columns: []string{"username", "Age"}}).Execute(context.Background())
+	assert.NoError(t, err)
+	_, err = c.From("users").Insert(columnsRow{Name: "x"}, nil).Execute(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		`"username","status","profile","Age","created_at"`,
+		`"username"`,
+		`"username","Age"`,
+		`"name"`,
+	}, columns)
+
+	t.Run("Batches", func(t *testing.T) {
+		rows := []columnsRow{{Name: "a"}, {ID: 2, Name: "b", Note: "n"}}
+		for _, maxBytes := range []int{0, 1 << 20} {
+			columns = nil
+			_, err := NewQueryBuilder[columnsRow](c, "users").
+				InsertBatch(context.Background(), rows, nil, &BatchOptions{MaxBytes: maxBytes})
+			assert.NoError(t, err)
+			assert.Equal(t, []string{`"name","id","note"`}, columns)
+		}
+	})
+}
@@ -0,0 +1,343 @@
+package postgrest
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InsertCSV performs an INSERT with a text/csv body, whose first line holds
+// the column names. Loading CSV is much faster than JSON for large imports.
+// body is streamed as is; use EncodeCSV or StreamCSV to produce it from rows.
+func (q *QueryBuilder[T]) InsertCSV(body io.Reader, opts *InsertOptions) *FilterBuilder[interface{}] {
+	f := q.Insert(body, opts)
+	f.headers.Set("Content-Type", "text/csv")
+	return f
+}
+
+// UpsertCSV performs an UPSERT with a text/csv body like InsertCSV
+func (q *QueryBuilder[T]) UpsertCSV(body io.Reader, opts *UpsertOptions) *FilterBuilder[interface{}] {
+	f := q.Upsert(body, opts)
+	f.headers.Set("Content-Type", "text/csv")
+	return f
+}
+
+// EncodeCSV writes rows to w as CSV with a header line, in the format
+// expected by InsertCSV. T must be a struct or a pointer to one. Columns are
+// the exported fields in order, including those of exported embedded structs,
+// named by their csv tag, or else their json tag, or else the field name; a
+// tag of "-" leaves the field out, so that with DefaultToNull false the
+// column gets its default. nil values are written as NULL, times in RFC 3339,
+// encoding.TextMarshaler values as their text, and other slices, maps and
+// structs as JSON.
+func EncodeCSV[T any](w io.Writer, rows []T) error {
+	return writeCSV(w, slices.Values(rows))
+}
+
+// StreamCSV returns a request body that encodes the values of seq as CSV
+// while the request is being sent, like StreamJSON does for JSON
+func StreamCSV[T any](seq iter.Seq[T]) io.ReadCloser {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeCSV(writer, seq))
+	}()
+	return reader
+}
+
+// writeCSV writes the values of seq to w as CSV with a header line
+func writeCSV[T any](w io.Writer, seq iter.Seq[T]) error {
+	typ := reflect.TypeFor[T]()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot encode %v as CSV: rows must be structs", typ)
+	}
+	fields := structColumns(typ, csvTags, false)
+	if len(fields) == 0 {
+		return fmt.Errorf("cannot encode %v as CSV: no exported fields", typ)
+	}
+
+	buffered := bufio.NewWriter(w)
+	record := make([]string, len(fields))
+	nulls := make([]bool, len(fields))
+	for i, field := range fields {
+		record[i] = field.name
+	}
+	if err := writeCSVRecord(buffered, record, nulls); err != nil {
+		return err
+	}
+
+	for row := range seq {
+		value := reflect.ValueOf(row)
+		for value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return fmt.Errorf("cannot encode nil row as CSV")
+			}
+			value = value.Elem()
+		}
+		for i, field := range fields {
+			fieldValue, err := value.FieldByIndexErr(field.index)
+			if err != nil {
+				// nil embedded struct pointer
+				record[i], nulls[i] = "", true
+				continue
+			}
+			record[i], nulls[i], err = formatCSVValue(fieldValue)
+			if err != nil {
+				return fmt.Errorf("error encoding column %s: %w", field.name, err)
+			}
+		}
+		if err := writeCSVRecord(buffered, record, nulls); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
+}
+
+// formatCSVValue formats a field value for a CSV body, reporting whether it
+// is NULL
+func formatCSVValue(value reflect.Value) (string, bool, error) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return "", true, nil
+		}
+		if value.Kind() == reflect.Pointer && value.Type().Implements(textMarshalerType) {
+			break
+		}
+		value = value.Elem()
+	}
+
+	switch v := value.Interface().(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano), false, nil
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		return string(text), false, err
+	case []byte:
+		if v == nil {
+			return "", true, nil
+		}
+		return fmt.Sprintf(`\x%x`, v), false, nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), false, nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), false, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), false, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), false, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'g', -1, value.Type().Bits()), false, nil
+	case reflect.Slice, reflect.Map:
+		if value.IsNil() {
+			return "", true, nil
+		}
+	}
+	encoded, err := json.Marshal(value.Interface())
+	return string(encoded), false, err
+}
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// csvTags are the struct tags naming CSV columns, in order of precedence
+var csvTags = []string{"csv", "json"}
+
+// writeCSVRecord writes a CSV line, quoting fields that contain separators or
+// quotes, have surrounding spaces, or could be mistaken for NULL
+func writeCSVRecord(w *bufio.Writer, record []string, nulls []bool) error {
+	for i, field := range record {
+		if i > 0 {
+			if err := w.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		switch {
+		case nulls[i]:
+			field = "NULL"
+		case strings.ContainsAny(field, ",\"\r\n") || strings.TrimSpace(field) != field || strings.EqualFold(field, "null"):
+			field = `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+		}
+		if _, err := w.WriteString(field); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte('\n')
+}
+
+// DecodeCSV reads rows from a CSV body with a header line, as returned by
+// CSV, matching columns to fields by the same names EncodeCSV uses. Columns
+// without a field are ignored. An empty field leaves pointers, slices and maps
+// nil and other fields zero. Booleans may be t or f, and json columns and
+// fields of other slice, map and struct types are decoded as JSON.
+func DecodeCSV[T any](r io.Reader) ([]T, error) {
+	var rows []T
+	if err := decodeCSV(r, reflect.ValueOf(&rows).Elem()); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// decodeCSV appends the rows of a CSV body to rows, a settable slice of
+// structs or struct pointers
+func decodeCSV(r io.Reader, rows reflect.Value) error {
+	rowType := rows.Type().Elem()
+	structType := rowType
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot decode CSV into %v: rows must be structs", rows.Type())
+	}
+
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading CSV header: %w", err)
+	}
+
+	byName := make(map[string]columnField)
+	for _, field := range structColumns(structType, csvTags, false) {
+		byName[field.name] = field
+	}
+	columns := make([]*columnField, len(header))
+	for i, name := range header {
+		if field, ok := byName[name]; ok {
+			columns[i] = &field
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading CSV: %w", err)
+		}
+
+		row := reflect.New(structType)
+		for i, column := range columns {
+			if column == nil {
+				continue
+			}
+			if err := parseCSVValue(record[i], allocFieldByIndex(row.Elem(), column.index)); err != nil {
+				line, _ := reader.FieldPos(i)
+				return fmt.Errorf("error decoding column %s on line %d: %w", column.name, line, err)
+			}
+		}
+		if rowType.Kind() == reflect.Pointer {
+			rows.Set(reflect.Append(rows, row))
+		} else {
+			rows.Set(reflect.Append(rows, row.Elem()))
+		}
+	}
+}
+
+// allocFieldByIndex returns the nested field of value, allocating the nil
+// embedded struct pointers on the way
+func allocFieldByIndex(value reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(x)
+	}
+	return value
+}
+
+// parseCSVValue parses a CSV field into value
+func parseCSVValue(s string, value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if s == "" {
+			value.SetZero()
+			return nil
+		}
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return parseCSVValue(s, value.Elem())
+	case reflect.Interface:
+		if s == "" {
+			value.SetZero()
+		} else {
+			value.Set(reflect.ValueOf(s))
+		}
+		return nil
+	}
+
+	target := value.Addr().Interface()
+	switch target.(type) {
+	case *time.Time, encoding.TextUnmarshaler:
+		if s == "" {
+			value.SetZero()
+			return nil
+		}
+		return parseTextValue(s, target)
+	case *[]byte:
+		if s == "" {
+			value.SetZero()
+			return nil
+		}
+		hexText, ok := strings.CutPrefix(s, `\x`)
+		if !ok {
+			return fmt.Errorf("invalid bytea %q", s)
+		}
+		decoded, err := hex.DecodeString(hexText)
+		if err != nil {
+			return err
+		}
+		value.SetBytes(decoded)
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(s)
+		return nil
+	case reflect.Bool:
+		switch s {
+		case "":
+			value.SetBool(false)
+		case "t", "true":
+			value.SetBool(true)
+		case "f", "false":
+			value.SetBool(false)
+		default:
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		return nil
+	case reflect.Slice, reflect.Map, reflect.Struct, reflect.Array:
+		value.SetZero()
+		if s == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(s), target)
+	}
+	if s == "" {
+		value.SetZero()
+		return nil
+	}
+	return parseTextValue(s, target)
+}
@@ -0,0 +1,295 @@
+package postgrest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/supabase-community/postgrest-go/pgtypes"
+)
+
+type CSVAudit struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type csvUser struct {
+	ID       int                   `json:"id" csv:"-"`
+	Username string                `json:"username"`
+	Status   *string               `json:"status"`
+	Age      float64               `json:"age,omitempty"`
+	Tags     pgtypes.Array[string] `json:"tags"`
+	Meta     map[string]int        `json:"meta"`
+	Note     string                `csv:"note"`
+	CSVAudit
+	secret string
+}
+
+func TestEncodeCSV(t *testing.T) {
+	online := "ONLINE"
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := []csvUser{
+		{ID: 1, Username: "kiwicopple", Status: &online, Age: 31.5, Tags: pgtypes.Array[string]{"a", "b c"},
+			Meta: map[string]int{"x": 1}, Note: `says "hi", twice`, CSVAudit: CSVAudit{created}},
+		{ID: 2, Username: "NULL", Note: " padded", CSVAudit: CSVAudit{created}},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, EncodeCSV(&buf, rows))
+	assert.Equal(t, "username,status,age,tags,meta,note,created_at\n"+
+		`kiwicopple,ONLINE,31.5,"{a,""b c""}","{""x"":1}","says ""hi"", twice",2024-01-02T03:04:05Z`+"\n"+
+		`"NULL",NULL,0,{},NULL," padded",2024-01-02T03:04:05Z`+"\n", buf.String())
+
+	t.Run("Pointers", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, EncodeCSV(&buf, []*csvUser{&rows[0]}))
+		assert.Contains(t, buf.String(), "kiwicopple,ONLINE")
+	})
+
+	t.Run("NotStructs", func(t *testing.T) {
+		assert.Error(t, EncodeCSV(io.Discard, []int{1}))
+	})
+
+	t.Run("WriteError", func(t *testing.T) {
+		many := slices.Repeat(rows, 500)
+		assert.ErrorIs(t, EncodeCSV(failingWriter{}, many), io.ErrClosedPipe)
+	})
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+// csvNewUser is a row of the users table of the test schema
+type csvNewUser struct {
+	ID              int                `json:"id" csv:"-"`
+	Username        string             `json:"username"`
+	Status          *string            `json:"status"`
+	FavoriteNumbers pgtypes.Array[int] `json:"favorite_numbers"`
+	Data            map[string]int     `json:"data"`
+}
+
+func TestQueryBuilder_InsertCSV(t *testing.T) {
+	c := createClient(t)
+	requests := recordRequests(c)
+
+	t.Run("Insert", func(t *testing.T) {
+		var body []byte
+		if mockResponses {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			httpmock.RegisterRegexpResponder("POST", mockPath, func(req *http.Request) (*http.Response, error) {
+				assert.Empty(t, req.URL.Query().Get("columns"))
+				var err error
+				body, err = io.ReadAll(req.Body)
+				return httpmock.NewStringResponse(201, ""), err
+			})
+		}
+
+		// The insert is rolled back when running against a real server
+		rows := []csvNewUser{{Username: "csvuser1"}, {Username: "csvuser2", Data: map[string]int{"x": 1}}}
+		response, err := NewQueryBuilder[csvNewUser](c, "users").
+			InsertCSV(StreamCSV(slices.Values(rows)), &InsertOptions{DefaultToNull: false}).
+			Rollback().
+			Execute(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 201, response.Status)
+		if assert.Len(t, requests.requests, 1) {
+			req := requests.requests[0]
+			assert.Equal(t, []string{"text/csv"}, req.Header.Values("Content-Type"))
+			assert.Contains(t, req.Header.Get("Prefer"), "missing=default")
+		}
+		if mockResponses {
+			assert.Equal(t, "username,status,favorite_numbers,data\n"+
+				"csvuser1,NULL,{},NULL\n"+
+				`csvuser2,NULL,{},"{""x"":1}"`+"\n", string(body))
+		}
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		requests.prefer()
+		if mockResponses {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			httpmock.RegisterRegexpResponder("POST", mockPath, func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, "username", req.URL.Query().Get("on_conflict"))
+				return httpmock.NewStringResponse(201, ""), nil
+			})
+		}
+
+		// kiwicopple is already offline
+		_, err := c.From("users").
+			UpsertCSV(bytes.NewBufferString("username,status\nkiwicopple,OFFLINE\n"), &UpsertOptions{OnConflict: "username"}).
+			Execute(context.Background())
+		assert.NoError(t, err)
+		if assert.Len(t, requests.requests, 1) {
+			req := requests.requests[0]
+			assert.Equal(t, []string{"text/csv"}, req.Header.Values("Content-Type"))
+			assert.Contains(t, req.Header.Get("Prefer"), "resolution=merge-duplicates")
+		}
+	})
+}
+
+type csvProfile struct {
+	ID       int                   `json:"id"`
+	Username *string               `json:"username"`
+	Active   bool                  `json:"active"`
+	Score    float64               `json:"score"`
+	Joined   pgtypes.Date          `json:"joined"`
+	Tags     pgtypes.Array[string] `json:"tags"`
+	Meta     map[string]int        `json:"meta"`
+	Avatar   []byte                `json:"avatar"`
+	*CSVAudit
+}
+
+const csvProfiles = "id,username,active,score,joined,tags,meta,avatar,created_at,ignored\n" +
+	`1,kiwicopple,t,1.5,2024-01-02,"{a,""b c""}","{""x"":1}",\x0102,2024-01-02 03:04:05+00,x` + "\n" +
+	"2,,f,,,,,,,\n"
+
+func TestDecodeCSV(t *testing.T) {
+	rows, err := DecodeCSV[csvProfile](strings.NewReader(csvProfiles))
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 2) {
+		assert.Equal(t, 1, rows[0].ID)
+		assert.Equal(t, "kiwicopple", *rows[0].Username)
+		assert.True(t, rows[0].Active)
+		assert.Equal(t, 1.5, rows[0].Score)
+		assert.Equal(t, pgtypes.NewDate(2024, time.January, 2), rows[0].Joined)
+		assert.Equal(t, pgtypes.Array[string]{"a", "b c"}, rows[0].Tags)
+		assert.Equal(t, map[string]int{"x": 1}, rows[0].Meta)
+		assert.Equal(t, []byte{1, 2}, rows[0].Avatar)
+		assert.True(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Equal(rows[0].CreatedAt))
+
+		assert.Equal(t, csvProfile{ID: 2, CSVAudit: &CSVAudit{}}, rows[1])
+	}
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := DecodeCSV[csvProfile](strings.NewReader("id\nabc\n"))
+		assert.ErrorContains(t, err, "column id on line 2")
+
+		_, err = DecodeCSV[int](strings.NewReader("id\n1\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		rows, err := DecodeCSV[*csvProfile](strings.NewReader(""))
+		assert.NoError(t, err)
+		assert.Empty(t, rows)
+	})
+}
+
+// csvStatus is a row of the users table of the test schema, as selected by
+// csvStatusColumns
+type csvStatus struct {
+	Username        string             `json:"username"`
+	Status          *string            `json:"status"`
+	AgeRange        string             `json:"age_range"`
+	FavoriteNumbers pgtypes.Array[int] `json:"favorite_numbers"`
+}
+
+const csvStatusColumns = "username,status,age_range,favorite_numbers"
+
+const csvStatuses = csvStatusColumns + "\n" +
+	`kiwicopple,OFFLINE,"[25,35)",` + "\n" +
+	`supabot,ONLINE,"[1,2)",` + "\n"
+
+func TestBuilder_CSVResponses(t *testing.T) {
+	c := createClient(t)
+	requests := recordRequests(c)
+	if mockResponses {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterRegexpResponder("GET", mockPath, func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(200, csvStatuses)
+			resp.Header.Set("Content-Range", "0-1/2")
+			return resp, nil
+		})
+	}
+	selectStatuses := func(opts *SelectOptions) *FilterBuilder[[]map[string]interface{}] {
+		return c.From("users").
+			Select(csvStatusColumns, opts).
+			In("username", []interface{}{"kiwicopple", "supabot"})
+	}
+
+	t.Run("ExecuteTo", func(t *testing.T) {
+		var rows []csvStatus
+		count, err := selectStatuses(&SelectOptions{Count: "exact"}).
+			Order("username", nil).
+			CSV().
+			ExecuteTo(context.Background(), &rows)
+		assert.NoError(t, err)
+		if assert.Len(t, rows, 2) {
+			assert.Equal(t, "kiwicopple", rows[0].Username)
+			assert.Equal(t, "OFFLINE", *rows[0].Status)
+			assert.Equal(t, "[25,35)", rows[0].AgeRange)
+			assert.Nil(t, rows[0].FavoriteNumbers)
+		}
+		if assert.NotNil(t, count) {
+			assert.Equal(t, int64(2), *count)
+		}
+		if assert.NotEmpty(t, requests.requests) {
+			assert.Equal(t, "text/csv", requests.requests[0].Header.Get("Accept"))
+		}
+	})
+
+	t.Run("Execute", func(t *testing.T) {
+		response, err := NewQueryBuilder[csvStatus](c, "users").
+			Select(csvStatusColumns, nil).
+			In("username", []interface{}{"kiwicopple", "supabot"}).
+			Order("username", nil).
+			SetHeader("Accept", "text/csv").
+			Execute(context.Background())
+		assert.NoError(t, err)
+		if assert.Len(t, response.Data, 2) {
+			assert.Equal(t, "supabot", response.Data[1].Username)
+		}
+	})
+
+	t.Run("ExecuteNotStructs", func(t *testing.T) {
+		statuses, _ := url.Parse(c.Transport.baseURL.String() + "/users?select=" + csvStatusColumns)
+		response, err := NewBuilder[map[string]interface{}](c, "GET", statuses, nil).
+			SetHeader("Accept", "text/csv").
+			Execute(context.Background())
+		assert.EqualError(t, err, "cannot decode CSV into map[string]interface {}: rows must be structs")
+		assert.Nil(t, response)
+
+		_, err = NewQueryBuilder[int](c, "users").Select(csvStatusColumns, nil).
+			SetHeader("Accept", "text/csv").
+			Execute(context.Background())
+		assert.EqualError(t, err, "error decoding CSV response: cannot decode CSV into []int: rows must be structs")
+	})
+
+	t.Run("ExecuteToWriter", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := selectStatuses(nil).Order("username", nil).CSV().ExecuteToWriter(context.Background(), &buf)
+		assert.NoError(t, err)
+		assert.Equal(t, csvStatuses, buf.String())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		if mockResponses {
+			httpmock.RegisterRegexpResponder("GET", mockPath, httpmock.NewStringResponder(400, `{"code":"42703","message":"column users.nope does not exist"}`))
+		}
+
+		var buf bytes.Buffer
+		_, err := c.From("users").Select("nope", nil).CSV().ExecuteToWriter(context.Background(), &buf)
+		var pgErr *PostgrestError
+		if assert.ErrorAs(t, err, &pgErr) {
+			assert.Equal(t, "42703", pgErr.Code)
+		}
+		assert.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,51 @@
+package postgrest
+
+import (
+	"context"
+	"net/http"
+)
+
+// dryRunKey is the context key of WithDryRun
+type dryRunKey struct{}
+
+// WithDryRun returns a context whose mutation and RPC requests are sent as
+// dry runs, like those of a client with SetDryRun. When a query has an
+// AbortSignal, the dry run is read from that context instead.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether requests sent with ctx are dry runs
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// SetDryRun sends every mutation and RPC of the client as a dry run: with
+// Prefer tx=rollback, the transaction is rolled back after the request, and
+// with return=representation the response holds the rows that would have
+// changed. handling=strict makes PostgREST fail rather than commit when it
+// doesn't allow overriding the transaction end, which requires db-tx-end to
+// be rollback-allow-override or commit-allow-override.
+func (c *Client) SetDryRun(enabled bool) *Client {
+	c.dryRun = enabled
+	return c
+}
+
+// applyDryRun adds the dry run preferences to req, a mutation or RPC sent
+// with ctx by a dry run client, or with a dry run context. They are only set
+// on req, so that executing the builder again without a dry run context
+// commits.
+func (b *Builder[T]) applyDryRun(ctx context.Context, req *http.Request) {
+	if b.method == "GET" || b.method == "HEAD" {
+		return
+	}
+	if !IsDryRun(ctx) && (b.client == nil || !b.client.dryRun) {
+		return
+	}
+	prefs := parsePreferences(req.Header.Values("Prefer"))
+	prefs["tx"] = "rollback"
+	prefs["return"] = "representation"
+	prefs["handling"] = "strict"
+	req.Header.Set("Prefer", prefs.String())
+}
@@ -0,0 +1,85 @@
+package postgrest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRun(t *testing.T) {
+	c := createClient(t)
+	requests := recordRequests(c)
+	if mockResponses {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		responder := func(req *http.Request) (*http.Response, error) {
+			resp, _ := httpmock.NewJsonResponse(200, []map[string]interface{}{{"username": "supabot", "status": "OFFLINE"}})
+			if req.Method != "GET" {
+				resp.Header.Set("Preference-Applied", "tx=rollback, return=representation")
+			}
+			return resp, nil
+		}
+		for _, method := range []string{"GET", "POST", "PATCH", "DELETE"} {
+			httpmock.RegisterRegexpResponder(method, mockPath, responder)
+		}
+	}
+
+	t.Run("Client", func(t *testing.T) {
+		c := createClient(t).SetDryRun(true).Schema("public")
+		requests := recordRequests(c)
+
+		response, err := c.From("users").
+			Update(map[string]interface{}{"status": "OFFLINE"}, &UpdateOptions{Returning: "minimal"}).
+			Eq("username", "supabot").
+			Execute(context.Background())
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Data)
+		assert.Equal(t, "rollback", response.PreferenceApplied["tx"])
+
+		_, err = c.Rpc("get_status", map[string]interface{}{"name_param": "supabot"}, nil).Execute(context.Background())
+		assert.NoError(t, err)
+		_, err = c.From("users").Select("*", nil).Execute(context.Background())
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"handling=strict, return=representation, tx=rollback",
+			"handling=strict, return=representation, tx=rollback",
+			"",
+		}, requests.prefer())
+
+		if !mockResponses {
+			// The update was rolled back
+			var rows []map[string]interface{}
+			_, err = c.From("users").Select("status", nil).Eq("username", "supabot").ExecuteTo(context.Background(), &rows)
+			assert.NoError(t, err)
+			assert.Equal(t, []map[string]interface{}{{"status": "ONLINE"}}, rows)
+		}
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		_, err := c.From("users").Delete(nil).Eq("username", "nobody").Execute(WithDryRun(context.Background()))
+		assert.NoError(t, err)
+		_, err = c.From("users").Delete(nil).Eq("username", "nobody").Execute(context.Background())
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"handling=strict, return=representation, tx=rollback", ""}, requests.prefer())
+		assert.True(t, IsDryRun(WithDryRun(context.Background())))
+		assert.False(t, IsDryRun(context.Background()))
+	})
+
+	t.Run("ReExecuted", func(t *testing.T) {
+		update := c.From("users").
+			Update(map[string]interface{}{"status": "ONLINE"}, &UpdateOptions{Returning: "minimal"}).
+			Eq("username", "supabot")
+		_, err := update.Execute(WithDryRun(context.Background()))
+		assert.NoError(t, err)
+		_, err = update.Execute(context.Background())
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"handling=strict, return=representation, tx=rollback", "return=minimal"}, requests.prefer())
+	})
+}
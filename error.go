@@ -1,5 +1,7 @@
 package postgrest
 
+import "encoding/json"
+
 // PostgrestError represents an error response from PostgREST
 // https://postgrest.org/en/stable/api.html?highlight=options#errors-and-http-status-codes
 type PostgrestError struct {
@@ -22,3 +24,25 @@ func NewPostgrestError(message, details, hint, code string) *PostgrestError {
 		Code:    code,
 	}
 }
+
+// errorFromData builds a PostgrestError from a decoded PostgREST error body
+func errorFromData(data map[string]interface{}) *PostgrestError {
+	field := func(name string) string {
+		value, _ := data[name].(string)
+		return value
+	}
+	return NewPostgrestError(field("message"), field("details"), field("hint"), field("code"))
+}
+
+// errorFromBody builds a PostgrestError from a raw PostgREST error body,
+// falling back to the body text or status when it is not a JSON object
+func errorFromBody(body []byte, status string) *PostgrestError {
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err == nil {
+		return errorFromData(data)
+	}
+	if len(body) == 0 {
+		return NewPostgrestError(status, "", "", "")
+	}
+	return NewPostgrestError(string(body), "", "", "")
+}
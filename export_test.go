@@ -1,8 +1,10 @@
 package postgrest
 
 import (
+	"net/http"
 	"os"
 	"regexp"
+	"sync"
 	"testing"
 )
 
@@ -42,6 +44,10 @@ func createClient(t *testing.T) *Client {
 	url := os.Getenv(urlEnv)
 	if url == "" {
 		url = "http://mock.xyz"
+		// Tests that don't create a client, such as the Ping tests, must not
+		// see mocking enabled by an earlier test
+		previous := mockResponses
+		t.Cleanup(func() { mockResponses = previous })
 		mockResponses = true
 
 		var err error
@@ -59,3 +65,35 @@ func createClient(t *testing.T) *Client {
 
 	return NewClient(url, "", headers)
 }
+
+// requestRecorder keeps the requests a client sends before passing them on,
+// so tests can check them against mocked and real responses alike
+type requestRecorder struct {
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+func recordRequests(c *Client) *requestRecorder {
+	r := &requestRecorder{}
+	c.Transport.Parent = r
+	return r
+}
+
+func (r *requestRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	r.requests = append(r.requests, req)
+	r.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// prefer returns the Prefer header of each recorded request, and forgets them
+func (r *requestRecorder) prefer() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prefer := make([]string, len(r.requests))
+	for i, req := range r.requests {
+		prefer[i] = req.Header.Get("Prefer")
+	}
+	r.requests = nil
+	return prefer
+}
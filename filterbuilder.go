@@ -2,9 +2,11 @@ package postgrest
 
 import (
 	"context"
+	"encoding"
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -14,7 +16,42 @@ type FilterBuilder[T any] struct {
 	*Builder[T]
 }
 
-var filterOperators = []string{"eq", "neq", "gt", "gte", "lt", "lte", "like", "ilike", "is", "in", "cs", "cd", "sl", "sr", "nxl", "nxr", "adj", "ov", "fts", "plfts", "phfts", "wfts"}
+// Operator is a PostgREST filter operator
+type Operator string
+
+// Filter operators supported by PostgREST
+const (
+	OpEq             Operator = "eq"
+	OpNeq            Operator = "neq"
+	OpGt             Operator = "gt"
+	OpGte            Operator = "gte"
+	OpLt             Operator = "lt"
+	OpLte            Operator = "lte"
+	OpLike           Operator = "like"
+	OpIlike          Operator = "ilike"
+	OpMatch          Operator = "match"
+	OpIMatch         Operator = "imatch"
+	OpIs             Operator = "is"
+	OpIsDistinct     Operator = "isdistinct"
+	OpIn             Operator = "in"
+	OpContains       Operator = "cs"
+	OpContainedBy    Operator = "cd"
+	OpStrictlyLeft   Operator = "sl"
+	OpStrictlyRight  Operator = "sr"
+	OpNotExtendRight Operator = "nxr"
+	OpNotExtendLeft  Operator = "nxl"
+	OpAdjacent       Operator = "adj"
+	OpOverlaps       Operator = "ov"
+	OpFts            Operator = "fts"
+	OpPlainFts       Operator = "plfts"
+	OpPhraseFts      Operator = "phfts"
+	OpWebFts         Operator = "wfts"
+)
+
+var filterOperators = []Operator{OpEq, OpNeq, OpGt, OpGte, OpLt, OpLte, OpLike, OpIlike, OpMatch, OpIMatch, OpIs, OpIsDistinct, OpIn, OpContains, OpContainedBy, OpStrictlyLeft, OpStrictlyRight, OpNotExtendLeft, OpNotExtendRight, OpAdjacent, OpOverlaps, OpFts, OpPlainFts, OpPhraseFts, OpWebFts}
+
+// modifierOperators are the operators that accept the (any) and (all) modifiers
+var modifierOperators = []Operator{OpEq, OpGt, OpGte, OpLt, OpLte, OpLike, OpIlike, OpMatch, OpIMatch}
 
 func (f *FilterBuilder[T]) appendFilter(column, filterValue string) *FilterBuilder[T] {
 	query := f.url.Query()
@@ -23,18 +60,21 @@ func (f *FilterBuilder[T]) appendFilter(column, filterValue string) *FilterBuild
 	return f
 }
 
-func isOperator(value string) bool {
-	for _, op := range filterOperators {
-		if op == value {
-			return true
-		}
-	}
-	return false
+func isOperator(value Operator) bool {
+	return slices.Contains(filterOperators, value)
+}
+
+// invalidOperatorError is recorded for a filter with an unknown operator, so
+// that the query fails instead of being sent without the filter
+func invalidOperatorError(operator Operator) error {
+	return fmt.Errorf("invalid filter operator %q", operator)
 }
 
-// Filter adds a filtering operator to the query
-func (f *FilterBuilder[T]) Filter(column, operator, value string) *FilterBuilder[T] {
+// Filter adds a filtering operator to the query. An unknown operator is
+// returned as an error when the query is executed.
+func (f *FilterBuilder[T]) Filter(column string, operator Operator, value string) *FilterBuilder[T] {
 	if !isOperator(operator) {
+		f.setErr(invalidOperatorError(operator))
 		return f
 	}
 	return f.appendFilter(column, fmt.Sprintf("%s.%s", operator, value))
@@ -100,14 +140,81 @@ func (f *FilterBuilder[T]) IlikeAnyOf(column string, patterns []string) *FilterB
 	return f.appendFilter(column, fmt.Sprintf("ilike(any).{%s}", strings.Join(patterns, ",")))
 }
 
+// RegexMatch matches only rows where column matches the POSIX regular expression pattern case-sensitively
+func (f *FilterBuilder[T]) RegexMatch(column, pattern string) *FilterBuilder[T] {
+	return f.appendFilter(column, fmt.Sprintf("match.%s", pattern))
+}
+
+// RegexIMatch matches only rows where column matches the POSIX regular expression pattern case-insensitively
+func (f *FilterBuilder[T]) RegexIMatch(column, pattern string) *FilterBuilder[T] {
+	return f.appendFilter(column, fmt.Sprintf("imatch.%s", pattern))
+}
+
+// AnyOf matches only rows where column satisfies operator for any of values.
+// Only eq, gt, gte, lt, lte, like, ilike, match and imatch accept the modifier;
+// other operators are returned as an error when the query is executed.
+func (f *FilterBuilder[T]) AnyOf(column string, operator Operator, values []interface{}) *FilterBuilder[T] {
+	if !slices.Contains(modifierOperators, operator) {
+		f.setErr(fmt.Errorf("operator %q doesn't accept the any modifier", operator))
+		return f
+	}
+	return f.appendFilter(column, fmt.Sprintf("%s(any).%s", operator, arrayLiteral(values)))
+}
+
+// AllOf matches only rows where column satisfies operator for all of values.
+// Only eq, gt, gte, lt, lte, like, ilike, match and imatch accept the modifier;
+// other operators are returned as an error when the query is executed.
+func (f *FilterBuilder[T]) AllOf(column string, operator Operator, values []interface{}) *FilterBuilder[T] {
+	if !slices.Contains(modifierOperators, operator) {
+		f.setErr(fmt.Errorf("operator %q doesn't accept the all modifier", operator))
+		return f
+	}
+	return f.appendFilter(column, fmt.Sprintf("%s(all).%s", operator, arrayLiteral(values)))
+}
+
 // Is matches only rows where column IS value
 func (f *FilterBuilder[T]) Is(column string, value interface{}) *FilterBuilder[T] {
 	return f.appendFilter(column, fmt.Sprintf("is.%v", value))
 }
 
+// IsDistinct matches only rows where column IS DISTINCT FROM value, treating NULL as a comparable value
+func (f *FilterBuilder[T]) IsDistinct(column string, value interface{}) *FilterBuilder[T] {
+	return f.appendFilter(column, fmt.Sprintf("isdistinct.%v", value))
+}
+
 // In matches only rows where column is included in the values array
 func (f *FilterBuilder[T]) In(column string, values []interface{}) *FilterBuilder[T] {
-	postgrestReservedCharsRegexp := regexp.MustCompile(`[,()]`)
+	elements := inListValues(values)
+	f.inFilters = append(f.inFilters, inFilter{column: column, values: elements})
+	return f.appendFilter(column, formatInList(elements))
+}
+
+// formatInList formats the elements of an in filter
+func formatInList(elements []string) string {
+	return fmt.Sprintf("in.(%s)", strings.Join(elements, ","))
+}
+
+var postgrestReservedCharsRegexp = regexp.MustCompile(`[,()]`)
+
+// arrayLiteral formats values as a Postgres array literal, quoting elements
+// that contain characters with a special meaning in arrays
+func arrayLiteral(values []interface{}) string {
+	elements := make([]string, len(values))
+	for i, v := range values {
+		elem := fmt.Sprintf("%v", v)
+		if elem == "" || strings.ContainsAny(elem, ",{}\"\\ ") {
+			elem = strings.ReplaceAll(elem, `\`, `\\`)
+			elem = strings.ReplaceAll(elem, `"`, `\"`)
+			elem = `"` + elem + `"`
+		}
+		elements[i] = elem
+	}
+	return "{" + strings.Join(elements, ",") + "}"
+}
+
+// inListValues formats values as the elements of an in.(...) list, quoting
+// those containing reserved characters
+func inListValues(values []interface{}) []string {
 	var cleanedValues []string
 	for _, v := range values {
 		valStr := fmt.Sprintf("%v", v)
@@ -117,12 +224,18 @@ func (f *FilterBuilder[T]) In(column string, values []interface{}) *FilterBuilde
 			cleanedValues = append(cleanedValues, valStr)
 		}
 	}
-	return f.appendFilter(column, fmt.Sprintf("in.(%s)", strings.Join(cleanedValues, ",")))
+	return cleanedValues
 }
 
 // Contains matches only rows where column contains every element appearing in value
 func (f *FilterBuilder[T]) Contains(column string, value interface{}) *FilterBuilder[T] {
 	switch v := value.(type) {
+	case postgresRange:
+		return f.appendFilter(column, fmt.Sprintf("cs.%s", v.rangeLiteral()))
+	case encoding.TextMarshaler:
+		// typed arrays such as pgtypes.Array
+		text, _ := v.MarshalText()
+		return f.appendFilter(column, fmt.Sprintf("cs.%s", text))
 	case string:
 		// range types
 		return f.appendFilter(column, fmt.Sprintf("cs.%s", v))
@@ -143,6 +256,12 @@ func (f *FilterBuilder[T]) Contains(column string, value interface{}) *FilterBui
 // ContainedBy matches only rows where every element appearing in column is contained by value
 func (f *FilterBuilder[T]) ContainedBy(column string, value interface{}) *FilterBuilder[T] {
 	switch v := value.(type) {
+	case postgresRange:
+		return f.appendFilter(column, fmt.Sprintf("cd.%s", v.rangeLiteral()))
+	case encoding.TextMarshaler:
+		// typed arrays such as pgtypes.Array
+		text, _ := v.MarshalText()
+		return f.appendFilter(column, fmt.Sprintf("cd.%s", text))
 	case string:
 		// range types
 		return f.appendFilter(column, fmt.Sprintf("cd.%s", v))
@@ -160,34 +279,41 @@ func (f *FilterBuilder[T]) ContainedBy(column string, value interface{}) *Filter
 	}
 }
 
-// RangeGt matches only rows where every element in column is greater than any element in range
-func (f *FilterBuilder[T]) RangeGt(column, rangeValue string) *FilterBuilder[T] {
-	return f.appendFilter(column, fmt.Sprintf("sr.%s", rangeValue))
+// RangeGt matches only rows where every element in column is greater than any element in range.
+// rangeValue is a Range or a range literal such as "[1,10)".
+func (f *FilterBuilder[T]) RangeGt(column string, rangeValue interface{}) *FilterBuilder[T] {
+	return f.appendFilter(column, fmt.Sprintf("sr.%v", rangeValue))
 }
 
 // RangeGte matches only rows where every element in column is either contained in range or greater than any element in range
-func (f *FilterBuilder[T]) RangeGte(column, rangeValue string) *FilterBuilder[T] {
-	return f.appendFilter(column, fmt.Sprintf("nxl.%s", rangeValue))
+func (f *FilterBuilder[T]) RangeGte(column string, rangeValue interface{}) *FilterBuilder[T] {
+	return f.appendFilter(column, fmt.Sprintf("nxl.%v", rangeValue))
 }
 
 // RangeLt matches only rows where every element in column is less than any element in range
-func (f *FilterBuilder[T]) RangeLt(column, rangeValue string) *FilterBuilder[T] {
-	return f.appendFilter(column, fmt.Sprintf("sl.%s", rangeValue))
+func (f *FilterBuilder[T]) RangeLt(column string, rangeValue interface{}) *FilterBuilder[T] {
+	return f.appendFilter(column, fmt.Sprintf("sl.%v", rangeValue))
 }
 
 // RangeLte matches only rows where every element in column is either contained in range or less than any element in range
-func (f *FilterBuilder[T]) RangeLte(column, rangeValue string) *FilterBuilder[T] {
-	return f.appendFilter(column, fmt.Sprintf("nxr.%s", rangeValue))
+func (f *FilterBuilder[T]) RangeLte(column string, rangeValue interface{}) *FilterBuilder[T] {
+	return f.appendFilter(column, fmt.Sprintf("nxr.%v", rangeValue))
 }
 
 // RangeAdjacent matches only rows where column is mutually exclusive to range
-func (f *FilterBuilder[T]) RangeAdjacent(column, rangeValue string) *FilterBuilder[T] {
-	return f.appendFilter(column, fmt.Sprintf("adj.%s", rangeValue))
+func (f *FilterBuilder[T]) RangeAdjacent(column string, rangeValue interface{}) *FilterBuilder[T] {
+	return f.appendFilter(column, fmt.Sprintf("adj.%v", rangeValue))
 }
 
 // Overlaps matches only rows where column and value have an element in common
 func (f *FilterBuilder[T]) Overlaps(column string, value interface{}) *FilterBuilder[T] {
 	switch v := value.(type) {
+	case postgresRange:
+		return f.appendFilter(column, fmt.Sprintf("ov.%s", v.rangeLiteral()))
+	case encoding.TextMarshaler:
+		// typed arrays such as pgtypes.Array
+		text, _ := v.MarshalText()
+		return f.appendFilter(column, fmt.Sprintf("ov.%s", text))
 	case string:
 		// range
 		return f.appendFilter(column, fmt.Sprintf("ov.%s", v))
@@ -205,30 +331,36 @@ func (f *FilterBuilder[T]) Overlaps(column string, value interface{}) *FilterBui
 
 // TextSearchOptions contains options for text search
 type TextSearchOptions struct {
+	// Config is the text search configuration, e.g. "english"
 	Config string
-	Type   string // "plain", "phrase", or "websearch"
+	Type   TextSearchType
 }
 
-// TextSearch matches only rows where column matches the query string
+// TextSearch matches only rows where column matches the query string. An
+// unknown Type or a Config that isn't a valid identifier is returned as an
+// error when the query is executed.
 func (f *FilterBuilder[T]) TextSearch(column, query string, opts *TextSearchOptions) *FilterBuilder[T] {
-	var typePart string
-	if opts != nil {
-		switch opts.Type {
-		case "plain":
-			typePart = "pl"
-		case "phrase":
-			typePart = "ph"
-		case "websearch":
-			typePart = "w"
-		}
+	if opts == nil {
+		opts = &TextSearchOptions{}
 	}
-
-	configPart := ""
-	if opts != nil && opts.Config != "" {
-		configPart = fmt.Sprintf("(%s)", opts.Config)
+	value, err := textSearchFilter(opts.Type, opts.Config, query)
+	if err != nil {
+		f.setErr(err)
+		return f
 	}
+	return f.appendFilter(column, value)
+}
 
-	return f.appendFilter(column, fmt.Sprintf("%sfts%s.%s", typePart, configPart, query))
+// TextSearchQuery matches only rows where column matches a query built with
+// TSTerm, TSPrefix and TSPhrase, parsed with to_tsquery using config, or the
+// default configuration when config is empty
+func (f *FilterBuilder[T]) TextSearchQuery(column string, query TSQuery, config string) *FilterBuilder[T] {
+	value, err := textSearchFilter(TextSearchDefault, config, query.String())
+	if err != nil {
+		f.setErr(err)
+		return f
+	}
+	return f.appendFilter(column, value)
 }
 
 // Match matches only rows where each column in query keys is equal to its associated value
@@ -239,8 +371,13 @@ func (f *FilterBuilder[T]) Match(query map[string]interface{}) *FilterBuilder[T]
 	return f
 }
 
-// Not matches only rows which doesn't satisfy the filter
-func (f *FilterBuilder[T]) Not(column, operator string, value interface{}) *FilterBuilder[T] {
+// Not matches only rows which doesn't satisfy the filter. An unknown operator
+// is returned as an error when the query is executed.
+func (f *FilterBuilder[T]) Not(column string, operator Operator, value interface{}) *FilterBuilder[T] {
+	if !isOperator(operator) {
+		f.setErr(invalidOperatorError(operator))
+		return f
+	}
 	return f.appendFilter(column, fmt.Sprintf("not.%s.%v", operator, value))
 }
 
@@ -268,6 +405,29 @@ func (f *FilterBuilder[T]) Or(filters string, opts *OrOptions) *FilterBuilder[T]
 	return f
 }
 
+// AndOptions contains options for And
+type AndOptions struct {
+	ReferencedTable string
+}
+
+// And matches only rows which satisfy all of the filters. Filters are in
+// PostgREST syntax, e.g. "age.gte.18,or(status.eq.ONLINE,status.is.null)".
+func (f *FilterBuilder[T]) And(filters string, opts *AndOptions) *FilterBuilder[T] {
+	if opts == nil {
+		opts = &AndOptions{}
+	}
+
+	key := "and"
+	if opts.ReferencedTable != "" {
+		key = opts.ReferencedTable + ".and"
+	}
+
+	query := f.url.Query()
+	query.Set(key, fmt.Sprintf("(%s)", filters))
+	f.url.RawQuery = query.Encode()
+	return f
+}
+
 // Embed TransformBuilder methods
 func (f *FilterBuilder[T]) Select(columns string) *FilterBuilder[T] {
 	tb := &TransformBuilder[T]{Builder: f.Builder}
@@ -289,6 +449,16 @@ func (f *FilterBuilder[T]) Range(from, to int, opts *RangeOptions) *TransformBui
 	return tb.Range(from, to, opts)
 }
 
+func (f *FilterBuilder[T]) Rollback() *TransformBuilder[T] {
+	tb := &TransformBuilder[T]{Builder: f.Builder}
+	return tb.Rollback()
+}
+
+func (f *FilterBuilder[T]) MaxAffected(value int) *TransformBuilder[T] {
+	tb := &TransformBuilder[T]{Builder: f.Builder}
+	return tb.MaxAffected(value)
+}
+
 func (f *FilterBuilder[T]) Single() *Builder[T] {
 	tb := &TransformBuilder[T]{Builder: f.Builder}
 	return tb.Single()
@@ -299,6 +469,11 @@ func (f *FilterBuilder[T]) MaybeSingle() *Builder[T] {
 	return tb.MaybeSingle()
 }
 
+func (f *FilterBuilder[T]) CSV() *Builder[string] {
+	tb := &TransformBuilder[T]{Builder: f.Builder}
+	return tb.CSV()
+}
+
 // Execute executes the query and returns the response
 func (f *FilterBuilder[T]) Execute(ctx context.Context) (*PostgrestResponse[T], error) {
 	return f.Builder.Execute(ctx)
@@ -10,6 +10,7 @@ import (
 
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/supabase-community/postgrest-go/pgtypes"
 )
 
 func TestFilterBuilder_ExecuteTo(t *testing.T) {
@@ -810,15 +811,30 @@ func TestFilterBuilder_Filter(t *testing.T) {
 func TestFilterBuilder_Filter_InvalidOperator(t *testing.T) {
 	c := createClient(t)
 
-	// Filter with invalid operator should be ignored
+	// Filter with invalid operator fails instead of being dropped
 	response, err := c.From("users").
 		Select("*", nil).
 		Filter("age", "invalid", "25").
 		Execute(context.Background())
+	assert.EqualError(t, err, `invalid filter operator "invalid"`)
+	assert.Nil(t, response)
 
-	// Should not error, just ignore invalid operator
-	assert.NoError(t, err)
-	assert.NotNil(t, response)
+	_, err = c.From("users").
+		Delete(nil).
+		Not("age", "invalid", 25).
+		Execute(context.Background())
+	assert.EqualError(t, err, `invalid filter operator "invalid"`)
+
+	_, err = c.From("users").
+		SelectItems(nil, Col("username"), Embed("messages", Embed("channels").Filter("slug", "invalid", "x"))).
+		Execute(context.Background())
+	assert.EqualError(t, err, `invalid filter operator "invalid"`)
+
+	_, err = c.From("users").
+		Select("*", nil).
+		AnyOf("age", OpIs, []interface{}{1}).
+		Execute(context.Background())
+	assert.EqualError(t, err, `operator "is" doesn't accept the any modifier`)
 }
 
 func TestFilterBuilder_Select(t *testing.T) {
@@ -867,3 +883,258 @@ func TestFilterBuilder_MaybeSingle(t *testing.T) {
 		assert.NotNil(t, response)
 	}
 }
+
+func TestFilterBuilder_Count(t *testing.T) {
+	c := createClient(t)
+	if !mockResponses {
+		t.Skip("requires mocked responses")
+	}
+
+	t.Run("Exact", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterRegexpResponder("HEAD", mockPath, func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, []string{"count=exact"}, req.Header.Values("Prefer"))
+			assert.Empty(t, req.URL.Query().Get("offset"))
+			resp := httpmock.NewStringResponse(200, "")
+			resp.Header.Set("Content-Range", "0-9/42")
+			return resp, nil
+		})
+
+		count, err := c.From("users").Select("*", nil).Eq("status", "ONLINE").Range(10, 19, nil).Count(context.Background(), "exact")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), count)
+	})
+
+	t.Run("PlannedFallsBackToExact", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		var modes []string
+		httpmock.RegisterRegexpResponder("HEAD", mockPath, func(req *http.Request) (*http.Response, error) {
+			mode := req.Header.Get("Prefer")
+			modes = append(modes, mode)
+			resp := httpmock.NewStringResponse(200, "")
+			if mode == "count=planned" {
+				resp.Header.Set("Content-Range", "*/12")
+			} else {
+				resp.Header.Set("Content-Range", "*/7")
+			}
+			return resp, nil
+		})
+
+		count, err := c.From("users").Select("*", nil).Count(context.Background(), "planned")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), count)
+		assert.Equal(t, []string{"count=planned", "count=exact"}, modes)
+	})
+
+	t.Run("InvalidMode", func(t *testing.T) {
+		_, err := c.From("users").Select("*", nil).Count(context.Background(), "approximate")
+		assert.Error(t, err)
+	})
+}
+
+func TestFilterBuilder_Exists(t *testing.T) {
+	c := createClient(t)
+	if !mockResponses {
+		t.Skip("requires mocked responses")
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterRegexpResponder("HEAD", mockPath, func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "1", req.URL.Query().Get("limit"))
+		assert.Empty(t, req.Header.Values("Prefer"))
+		resp := httpmock.NewStringResponse(200, "")
+		if req.URL.Query().Get("username") == "eq.supabot" {
+			resp.Header.Set("Content-Range", "0-0/*")
+		} else {
+			resp.Header.Set("Content-Range", "*/*")
+		}
+		return resp, nil
+	})
+
+	exists, err := c.From("users").Select("*", nil).Eq("username", "supabot").Exists(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = c.From("users").Select("*", nil).Eq("username", "nobody").Exists(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestStreamRows(t *testing.T) {
+	c := createClient(t)
+	if !mockResponses {
+		t.Skip("requires mocked responses")
+	}
+
+	t.Run("Array", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterRegexpResponder("GET", mockPath, httpmock.NewStringResponder(200, ` [{"id":1,"name":"sean"}, {"id":2,"name":"patti"}]`))
+
+		var got []TestResult
+		query := NewQueryBuilder[TestResult](c, "users").Select("id, name", nil)
+		for row, err := range StreamRows(context.Background(), query.Builder) {
+			assert.NoError(t, err)
+			got = append(got, row)
+		}
+		assert.Equal(t, []TestResult{{ID: 1, Name: "sean"}, {ID: 2, Name: "patti"}}, got)
+	})
+
+	t.Run("StopEarly", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterRegexpResponder("GET", mockPath, httpmock.NewStringResponder(200, `[{"id":1},{"id":2},{"id":3}]`))
+
+		var rows int
+		for range StreamRows(context.Background(), c.From("users").Select("id", nil).Builder) {
+			rows++
+			break
+		}
+		assert.Equal(t, 1, rows)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterRegexpResponder("GET", mockPath, httpmock.NewStringResponder(400, `{"message":"column users.foo does not exist","code":"42703"}`))
+
+		var errs []error
+		for _, err := range StreamRows(context.Background(), c.From("users").Select("foo", nil).Builder) {
+			errs = append(errs, err)
+		}
+		if assert.Len(t, errs, 1) {
+			var pgErr *PostgrestError
+			assert.ErrorAs(t, errs[0], &pgErr)
+			assert.Equal(t, "42703", pgErr.Code)
+		}
+	})
+}
+
+func TestFilterBuilder_ExecuteTo_Error(t *testing.T) {
+	c := createClient(t)
+	if !mockResponses {
+		t.Skip("requires mocked responses")
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterRegexpResponder("GET", mockPath, httpmock.NewStringResponder(400, `{"message":"bad filter","code":"PGRST100"}`))
+
+	var got []TestResult
+	_, err := c.From("users").Select("*", nil).Eq("id", "x").ExecuteTo(context.Background(), &got)
+	assert.EqualError(t, err, "bad filter")
+	assert.Nil(t, got)
+}
+
+func TestFilterBuilder_Operators(t *testing.T) {
+	tests := []struct {
+		name     string
+		build    func(*FilterBuilder[[]map[string]interface{}]) *FilterBuilder[[]map[string]interface{}]
+		key      string
+		expected []string
+	}{
+		{
+			name: "RegexMatch",
+			build: func(fb *FilterBuilder[[]map[string]interface{}]) *FilterBuilder[[]map[string]interface{}] {
+				return fb.RegexMatch("username", "^supa").RegexIMatch("username", "BOT$")
+			},
+			key:      "username",
+			expected: []string{"match.^supa", "imatch.BOT$"},
+		},
+		{
+			name: "IsDistinct",
+			build: func(fb *FilterBuilder[[]map[string]interface{}]) *FilterBuilder[[]map[string]interface{}] {
+				return fb.IsDistinct("status", "ONLINE")
+			},
+			key:      "status",
+			expected: []string{"isdistinct.ONLINE"},
+		},
+		{
+			name: "AnyOf and AllOf",
+			build: func(fb *FilterBuilder[[]map[string]interface{}]) *FilterBuilder[[]map[string]interface{}] {
+				return fb.AnyOf("id", OpGt, []interface{}{1, 5}).AllOf("id", OpLte, []interface{}{10, 20})
+			},
+			key:      "id",
+			expected: []string{"gt(any).{1,5}", "lte(all).{10,20}"},
+		},
+		{
+			name: "AnyOf quotes elements",
+			build: func(fb *FilterBuilder[[]map[string]interface{}]) *FilterBuilder[[]map[string]interface{}] {
+				return fb.AnyOf("message", OpMatch, []interface{}{"a,b", `say "hi"`, "plain"})
+			},
+			key:      "message",
+			expected: []string{`match(any).{"a,b","say \"hi\"",plain}`},
+		},
+		{
+			name: "AnyOf rejects operators without modifiers",
+			build: func(fb *FilterBuilder[[]map[string]interface{}]) *FilterBuilder[[]map[string]interface{}] {
+				return fb.AnyOf("id", OpIn, []interface{}{1}).AllOf("id", OpIs, []interface{}{nil})
+			},
+			key: "id",
+		},
+		{
+			name: "Typed Not",
+			build: func(fb *FilterBuilder[[]map[string]interface{}]) *FilterBuilder[[]map[string]interface{}] {
+				return fb.Not("status", OpIsDistinct, "OFFLINE").Not("status", "unknown", "x")
+			},
+			key:      "status",
+			expected: []string{"not.isdistinct.OFFLINE"},
+		},
+		{
+			name: "And",
+			build: func(fb *FilterBuilder[[]map[string]interface{}]) *FilterBuilder[[]map[string]interface{}] {
+				return fb.And("id.gte.1,or(status.eq.ONLINE,status.is.null)", nil)
+			},
+			key:      "and",
+			expected: []string{"(id.gte.1,or(status.eq.ONLINE,status.is.null))"},
+		},
+		{
+			name: "And on referenced table",
+			build: func(fb *FilterBuilder[[]map[string]interface{}]) *FilterBuilder[[]map[string]interface{}] {
+				return fb.And("id.gt.1,id.lt.5", &AndOptions{ReferencedTable: "messages"})
+			},
+			key:      "messages.and",
+			expected: []string{"(id.gt.1,id.lt.5)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient("http://localhost:3000", "", nil)
+			testURL, _ := url.Parse("http://localhost:3000/test")
+			fb := &FilterBuilder[[]map[string]interface{}]{
+				Builder: NewBuilder[[]map[string]interface{}](client, "GET", testURL, nil),
+			}
+
+			assert.Equal(t, tt.expected, tt.build(fb).url.Query()[tt.key])
+		})
+	}
+}
+
+func TestFilterBuilder_PgtypesValues(t *testing.T) {
+	client := NewClient("http://localhost:3000", "", nil)
+
+	f := client.From("kitchen_sink").Select("*", nil).
+		Eq("datetime_pos_infinite_value", pgtypes.Timestamp{Infinity: pgtypes.PositiveInfinity}).
+		Lt("datetime_value", pgtypes.Timestamp{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}).
+		Eq("float_value", pgtypes.Numeric("99999.0")).
+		Contains("list_of_strings", pgtypes.Array[string]{"a", "b c"}).
+		Overlaps("list_of_datetimes", pgtypes.Array[pgtypes.Date]{pgtypes.NewDate(2024, time.January, 1)})
+
+	query, _ := url.ParseQuery(f.url.RawQuery)
+	assert.Equal(t, "eq.infinity", query.Get("datetime_pos_infinite_value"))
+	assert.Equal(t, "lt.2024-01-02T03:04:05", query.Get("datetime_value"))
+	assert.Equal(t, "eq.99999.0", query.Get("float_value"))
+	assert.Equal(t, `cs.{a,"b c"}`, query.Get("list_of_strings"))
+	assert.Equal(t, "ov.{2024-01-01}", query.Get("list_of_datetimes"))
+}
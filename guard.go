@@ -0,0 +1,76 @@
+package postgrest
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrUnfiltered is returned instead of sending an UPDATE or DELETE without
+// filters, which would affect every row of the table the user can access
+var ErrUnfiltered = errors.New("refusing to send an UPDATE or DELETE without filters")
+
+// AllowUnfiltered lets UPDATE and DELETE requests without filters be sent by
+// all queries of the client. By default they are refused with ErrUnfiltered.
+func (c *Client) AllowUnfiltered(allow bool) *Client {
+	c.allowUnfiltered = allow
+	return c
+}
+
+// SetMaxAffected limits the rows an UPDATE or DELETE of the client may
+// affect to max, with the strict handling of MaxAffected: a request that
+// would affect more fails and is rolled back. A MaxAffected set on a query
+// takes precedence. It is disabled when max is 0.
+func (c *Client) SetMaxAffected(max int) *Client {
+	c.maxAffected = max
+	return c
+}
+
+// AllowUnfiltered lets this UPDATE or DELETE be sent without filters,
+// affecting every row of the table the user can access
+func (b *Builder[T]) AllowUnfiltered() *Builder[T] {
+	b.allowUnfiltered = true
+	return b
+}
+
+// isMutation reports whether the request updates or deletes rows selected by
+// its filters
+func (b *Builder[T]) isMutation() bool {
+	return b.method == "PATCH" || b.method == "DELETE"
+}
+
+// guardMutation checks that an UPDATE or DELETE has filters unless allowed,
+// and applies the client's max-affected ceiling
+func (b *Builder[T]) guardMutation() error {
+	if !b.isMutation() || b.client == nil {
+		return nil
+	}
+	if !b.allowUnfiltered && !b.client.allowUnfiltered && !hasFilters(b.url.Query()) {
+		return ErrUnfiltered
+	}
+	if b.client.maxAffected > 0 {
+		if _, ok := parsePreferences(b.headers.Values("Prefer"))["max-affected"]; !ok {
+			setPreference(b.headers, "handling", "strict")
+			setPreference(b.headers, "max-affected", strconv.Itoa(b.client.maxAffected))
+		}
+	}
+	return nil
+}
+
+// hasFilters reports whether query holds a filter on the rows of the table.
+// Filters on embedded resources don't count, as they don't restrict the
+// rows of the table.
+func hasFilters(query map[string][]string) bool {
+	for key := range query {
+		switch key {
+		case "select", "order", "limit", "offset", "columns", "on_conflict":
+			continue
+		}
+		if strings.Contains(key, ".") && !strings.HasPrefix(key, "not.") {
+			// embedded filters and transforms, e.g. posts.order
+			continue
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package postgrest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_UnfilteredMutations(t *testing.T) {
+	c := createClient(t)
+	requests := recordRequests(c)
+	if mockResponses {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterRegexpResponder("PATCH", mockPath, httpmock.NewStringResponder(204, ""))
+		httpmock.RegisterRegexpResponder("DELETE", mockPath, httpmock.NewStringResponder(204, ""))
+	}
+
+	t.Run("Refused", func(t *testing.T) {
+		_, err := c.From("users").Delete(nil).Execute(context.Background())
+		assert.ErrorIs(t, err, ErrUnfiltered)
+
+		_, err = c.From("users").Update(map[string]interface{}{"status": "OFFLINE"}, nil).
+			Select("username").
+			Order("username", nil).
+			Execute(context.Background())
+		assert.ErrorIs(t, err, ErrUnfiltered)
+
+		_, err = c.From("users").Delete(nil).Eq("messages.id", 1).Execute(context.Background())
+		assert.ErrorIs(t, err, ErrUnfiltered)
+		assert.Empty(t, requests.prefer())
+	})
+
+	t.Run("Filtered", func(t *testing.T) {
+		_, err := c.From("users").Delete(nil).Eq("username", "nobody").Execute(context.Background())
+		assert.NoError(t, err)
+		_, err = c.From("users").Delete(nil).Or("username.eq.nobody,username.eq.noone", nil).Execute(context.Background())
+		assert.NoError(t, err)
+	})
+
+	// The unfiltered deletes are dry runs, so that the table is kept when
+	// running against a real server
+	t.Run("AllowedPerCall", func(t *testing.T) {
+		_, err := c.From("kitchen_sink").Delete(nil).AllowUnfiltered().Execute(WithDryRun(context.Background()))
+		assert.NoError(t, err)
+	})
+
+	t.Run("AllowedByClient", func(t *testing.T) {
+		unsafe := createClient(t).AllowUnfiltered(true).Schema("public")
+		_, err := unsafe.From("kitchen_sink").Delete(nil).Execute(WithDryRun(context.Background()))
+		assert.NoError(t, err)
+	})
+
+	t.Run("MaxAffected", func(t *testing.T) {
+		limited := createClient(t).SetMaxAffected(100).Schema("public")
+		requests := recordRequests(limited)
+		_, err := limited.From("users").Delete(nil).Eq("username", "nobody").Execute(context.Background())
+		assert.NoError(t, err)
+		_, err = limited.From("users").Delete(nil).Eq("username", "nobody").MaxAffected(5).Execute(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"handling=strict, max-affected=100", "handling=strict, max-affected=5"}, requests.prefer())
+	})
+}
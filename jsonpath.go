@@ -0,0 +1,92 @@
+package postgrest
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JSONPath is a json or jsonb column followed by a path into its value, such
+// as data->settings->>theme. Its String form can be passed as the column to
+// Select, every FilterBuilder method, Order and Col, and it can be used
+// directly as a SelectItem.
+type JSONPath struct {
+	column string
+	path   []string
+	cast   string
+	alias  string
+}
+
+// Path starts a JSON path at column
+func Path(column string) JSONPath {
+	return JSONPath{column: column}
+}
+
+// Key follows key with ->, keeping the value as json
+func (p JSONPath) Key(key string) JSONPath {
+	return p.with("->" + quoteJSONKey(key))
+}
+
+// TextKey follows key with ->>, extracting the value as text
+func (p JSONPath) TextKey(key string) JSONPath {
+	return p.with("->>" + quoteJSONKey(key))
+}
+
+// Index follows the array element at index with ->, keeping it as json.
+// Negative indexes count from the end of the array.
+func (p JSONPath) Index(index int) JSONPath {
+	return p.with("->" + strconv.Itoa(index))
+}
+
+// TextIndex follows the array element at index with ->>, extracting it as text
+func (p JSONPath) TextIndex(index int) JSONPath {
+	return p.with("->>" + strconv.Itoa(index))
+}
+
+// Cast casts the extracted value to typ. PostgREST only accepts casts in the
+// select list.
+func (p JSONPath) Cast(typ string) JSONPath {
+	p.cast = typ
+	return p
+}
+
+// As renames the extracted value in the result. By default PostgREST names it
+// after the last key of the path.
+func (p JSONPath) As(alias string) JSONPath {
+	p.alias = alias
+	return p
+}
+
+func (p JSONPath) with(segment string) JSONPath {
+	p.path = append(p.path[:len(p.path):len(p.path)], segment)
+	return p
+}
+
+// String returns the path as a column name for filters and ordering
+func (p JSONPath) String() string {
+	return p.column + strings.Join(p.path, "")
+}
+
+func (p JSONPath) selectString() string {
+	s := p.String()
+	if p.cast != "" {
+		s += "::" + p.cast
+	}
+	if p.alias != "" {
+		s = p.alias + ":" + s
+	}
+	return s
+}
+
+var plainJSONKeyRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// quoteJSONKey double quotes keys that PostgREST would otherwise parse as an
+// array index or as part of the surrounding syntax
+func quoteJSONKey(key string) string {
+	if plainJSONKeyRegexp.MatchString(key) {
+		return key
+	}
+	key = strings.ReplaceAll(key, `\`, `\\`)
+	key = strings.ReplaceAll(key, `"`, `\"`)
+	return `"` + key + `"`
+}
@@ -0,0 +1,60 @@
+package postgrest
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     JSONPath
+		expected string
+	}{
+		{"Column only", Path("data"), "data"},
+		{"Nested text key", Path("data").Key("settings").TextKey("theme"), "data->settings->>theme"},
+		{"Array indexes", Path("data").Key("tags").Index(0), "data->tags->0"},
+		{"Negative text index", Path("data").Key("tags").TextIndex(-1), "data->tags->>-1"},
+		{"Key with spaces and dots", Path("data").TextKey("first name.v2"), `data->>"first name.v2"`},
+		{"Numeric key", Path("data").Key("2024"), `data->"2024"`},
+		{"Key with quotes", Path("data").TextKey(`say "hi"\`), `data->>"say \"hi\"\\"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.path.String())
+		})
+	}
+
+	t.Run("Branching does not share segments", func(t *testing.T) {
+		settings := Path("data").Key("settings")
+		theme := settings.TextKey("theme")
+		lang := settings.TextKey("lang")
+		assert.Equal(t, "data->settings->>theme", theme.String())
+		assert.Equal(t, "data->settings->>lang", lang.String())
+	})
+
+	t.Run("Select item with cast and alias", func(t *testing.T) {
+		items := []SelectItem{Col("username"), Path("data").Key("age").Cast("int").As("age"), Path("data").TextKey("x y")}
+		assert.Equal(t, `username,age:data->age::int,data->>"x y"`, Columns(items...))
+	})
+
+	t.Run("Filters and order", func(t *testing.T) {
+		client := NewClient("http://localhost:3000", "", nil)
+		theme := Path("data").Key("settings").TextKey("theme")
+
+		f := client.From("users").
+			Select(Columns(Col("username"), theme), nil).
+			Eq(theme.String(), "dark").
+			Gt(Path("data").Key("age").String(), 21)
+		f.Order(theme.String(), nil)
+
+		query, _ := url.ParseQuery(f.url.RawQuery)
+		assert.Equal(t, "username,data->settings->>theme", query.Get("select"))
+		assert.Equal(t, "eq.dark", query.Get("data->settings->>theme"))
+		assert.Equal(t, "gt.21", query.Get("data->age"))
+		assert.Equal(t, "data->settings->>theme.asc", query.Get("order"))
+	})
+}
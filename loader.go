@@ -0,0 +1,194 @@
+package postgrest
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LoaderOptions contains options for NewLoader
+type LoaderOptions struct {
+	Select       string        // columns to select, defaults to "*"
+	Wait         time.Duration // how long keys are collected before a query is sent, defaults to 2ms
+	DisableCache bool          // forget results once delivered instead of caching them
+	// Chunk bounds the requests of batches too large for a single URL
+	Chunk *ChunkOptions
+}
+
+// Loader batches lookups of rows by key. Keys requested within the Wait
+// window are fetched with a single In query on column, and the rows are
+// handed back to each caller by the key returned by keyFunc. Results are
+// cached until cleared, so a Loader is meant to live for one incoming request,
+// such as a GraphQL operation.
+type Loader[K comparable, T any] struct {
+	client  *Client
+	table   string
+	column  string
+	keyFunc func(T) K
+	opts    LoaderOptions
+
+	mu      sync.Mutex
+	entries map[K]*loaderEntry[T]
+	batch   *loaderBatch[K, T]
+}
+
+// loaderEntry is the pending or cached result for a key
+type loaderEntry[T any] struct {
+	done chan struct{}
+	rows []T
+	err  error
+}
+
+// loaderBatch is the set of keys collected during one Wait window
+type loaderBatch[K comparable, T any] struct {
+	ctx     context.Context
+	keys    []K
+	entries []*loaderEntry[T]
+}
+
+// NewLoader returns a Loader for rows of table whose column matches the
+// requested keys. keyFunc returns the key of a row and must agree with the
+// value of column.
+func NewLoader[K comparable, T any](client *Client, table, column string, keyFunc func(T) K, opts *LoaderOptions) *Loader[K, T] {
+	l := &Loader[K, T]{
+		client:  client,
+		table:   table,
+		column:  column,
+		keyFunc: keyFunc,
+		entries: make(map[K]*loaderEntry[T]),
+	}
+	if opts != nil {
+		l.opts = *opts
+	}
+	if l.opts.Select == "" {
+		l.opts.Select = "*"
+	}
+	if l.opts.Wait <= 0 {
+		l.opts.Wait = 2 * time.Millisecond
+	}
+	return l
+}
+
+// Load returns the rows whose column equals key, or an empty slice when there
+// are none. A failed query returns its error to every caller in the batch and
+// is not cached.
+func (l *Loader[K, T]) Load(ctx context.Context, key K) ([]T, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return l.wait(ctx, l.enqueue(ctx, key))
+}
+
+// LoadMany returns the rows for each of keys, in the same order
+func (l *Loader[K, T]) LoadMany(ctx context.Context, keys []K) ([][]T, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	entries := make([]*loaderEntry[T], len(keys))
+	for i, key := range keys {
+		entries[i] = l.enqueue(ctx, key)
+	}
+
+	results := make([][]T, len(keys))
+	for i, entry := range entries {
+		rows, err := l.wait(ctx, entry)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = rows
+	}
+	return results, nil
+}
+
+// Clear removes key from the cache so the next Load fetches it again
+func (l *Loader[K, T]) Clear(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries, key)
+}
+
+// ClearAll empties the cache
+func (l *Loader[K, T]) ClearAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	clear(l.entries)
+}
+
+func (l *Loader[K, T]) wait(ctx context.Context, entry *loaderEntry[T]) ([]T, error) {
+	select {
+	case <-entry.done:
+		return entry.rows, entry.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// enqueue returns the entry for key, adding the key to the current batch and
+// starting one if none is collecting
+func (l *Loader[K, T]) enqueue(ctx context.Context, key K) *loaderEntry[T] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry, ok := l.entries[key]; ok {
+		return entry
+	}
+	entry := &loaderEntry[T]{done: make(chan struct{})}
+	l.entries[key] = entry
+
+	if l.batch == nil {
+		// The query outlives the caller that started the batch, since other
+		// callers wait on it too
+		batch := &loaderBatch[K, T]{ctx: context.WithoutCancel(ctx)}
+		l.batch = batch
+		time.AfterFunc(l.opts.Wait, func() { l.dispatch(batch) })
+	}
+	l.batch.keys = append(l.batch.keys, key)
+	l.batch.entries = append(l.batch.entries, entry)
+	return entry
+}
+
+// dispatch fetches the rows for a batch and delivers them to its entries
+func (l *Loader[K, T]) dispatch(batch *loaderBatch[K, T]) {
+	l.mu.Lock()
+	if l.batch == batch {
+		l.batch = nil
+	}
+	l.mu.Unlock()
+
+	values := make([]interface{}, len(batch.keys))
+	for i, key := range batch.keys {
+		values[i] = key
+	}
+
+	rowsByKey := make(map[K][]T)
+	response, err := NewQueryBuilder[T](l.client, l.table).
+		Select(l.opts.Select, nil).
+		In(l.column, values).
+		ExecuteChunked(batch.ctx, l.opts.Chunk)
+	if err == nil && response.Error != nil {
+		err = response.Error
+	}
+	if err == nil {
+		for _, row := range response.Data {
+			key := l.keyFunc(row)
+			rowsByKey[key] = append(rowsByKey[key], row)
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, entry := range batch.entries {
+		key := batch.keys[i]
+		entry.err = err
+		if err == nil {
+			entry.rows = rowsByKey[key]
+			if entry.rows == nil {
+				entry.rows = []T{}
+			}
+		}
+		if (err != nil || l.opts.DisableCache) && l.entries[key] == entry {
+			delete(l.entries, key)
+		}
+		close(entry.done)
+	}
+}
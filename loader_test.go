@@ -0,0 +1,137 @@
+package postgrest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type loaderMessage struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
+
+// registerMessages mocks a messages table with two messages per username,
+// recording the in filter of each request
+func registerMessages(failing bool) *[]string {
+	var mu sync.Mutex
+	var filters []string
+	httpmock.RegisterRegexpResponder("GET", mockPath, func(req *http.Request) (*http.Response, error) {
+		filter := req.URL.Query().Get("username")
+		mu.Lock()
+		filters = append(filters, filter)
+		mu.Unlock()
+		if failing {
+			return httpmock.NewJsonResponse(500, map[string]interface{}{"message": "batch failed"})
+		}
+
+		rows := []loaderMessage{}
+		names := strings.Split(strings.TrimSuffix(strings.TrimPrefix(filter, "in.("), ")"), ",")
+		for i, name := range names {
+			if name != "nobody" {
+				rows = append(rows, loaderMessage{ID: 2 * i, Username: name}, loaderMessage{ID: 2*i + 1, Username: name})
+			}
+		}
+		return httpmock.NewJsonResponse(200, rows)
+	})
+	return &filters
+}
+
+func TestLoader(t *testing.T) {
+	c := createClient(t)
+	if !mockResponses {
+		t.Skip("requires mocked responses")
+	}
+	byUsername := func(m loaderMessage) string { return m.Username }
+
+	t.Run("BatchesConcurrentLoads", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		filters := registerMessages(false)
+
+		loader := NewLoader(c, "messages", "username", byUsername, &LoaderOptions{Wait: 20 * time.Millisecond})
+		names := []string{"supabot", "awailas", "nobody", "supabot"}
+		results := make([][]loaderMessage, len(names))
+		var wg sync.WaitGroup
+		for i, name := range names {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rows, err := loader.Load(context.Background(), name)
+				assert.NoError(t, err)
+				results[i] = rows
+			}()
+		}
+		wg.Wait()
+
+		if assert.Len(t, *filters, 1) {
+			assert.Len(t, strings.Split((*filters)[0], ","), 3)
+		}
+		assert.Len(t, results[0], 2)
+		assert.Equal(t, "supabot", results[0][0].Username)
+		assert.Equal(t, "awailas", results[1][1].Username)
+		assert.Equal(t, []loaderMessage{}, results[2])
+		assert.Equal(t, results[0], results[3])
+	})
+
+	t.Run("CachesResults", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		filters := registerMessages(false)
+
+		loader := NewLoader(c, "messages", "username", byUsername, nil)
+		results, err := loader.LoadMany(context.Background(), []string{"supabot", "awailas"})
+		assert.NoError(t, err)
+		assert.Len(t, results, 2)
+
+		rows, err := loader.Load(context.Background(), "awailas")
+		assert.NoError(t, err)
+		assert.Equal(t, results[1], rows)
+		assert.Len(t, *filters, 1)
+
+		loader.Clear("awailas")
+		_, err = loader.Load(context.Background(), "awailas")
+		assert.NoError(t, err)
+		if assert.Len(t, *filters, 2) {
+			assert.Equal(t, "in.(awailas)", (*filters)[1])
+		}
+	})
+
+	t.Run("FansOutErrors", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		filters := registerMessages(true)
+
+		loader := NewLoader(c, "messages", "username", byUsername, nil)
+		_, err := loader.LoadMany(context.Background(), []string{"supabot", "awailas"})
+		assert.EqualError(t, err, "batch failed")
+
+		// failures are not cached
+		_, err = loader.Load(context.Background(), "supabot")
+		assert.EqualError(t, err, "batch failed")
+		assert.Len(t, *filters, 2)
+	})
+
+	t.Run("CallerContext", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		registerMessages(false)
+
+		loader := NewLoader(c, "messages", "username", byUsername, &LoaderOptions{Wait: 50 * time.Millisecond})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := loader.Load(ctx, "supabot")
+		assert.ErrorIs(t, err, context.Canceled)
+
+		// the batch started by the canceled caller still serves others
+		rows, err := loader.Load(context.Background(), "supabot")
+		assert.NoError(t, err)
+		assert.Len(t, rows, 2)
+	})
+}
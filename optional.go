@@ -0,0 +1,189 @@
+package postgrest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Optional is a column value that is either unset or set. Use it with the
+// omitzero option, or with UpdateFields, to leave unset columns out of a
+// request body, e.g. in a partial update:
+//
+//	type UserPatch struct {
+//		Username postgrest.Optional[string] `json:"username,omitzero"`
+//	}
+//
+// Unlike a pointer with omitempty, a set zero value is still sent.
+type Optional[T any] struct {
+	value T
+	set   bool
+}
+
+// Some returns an Optional set to value
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{value: value, set: true}
+}
+
+// Get returns the value and whether it is set
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.set
+}
+
+// IsSet reports whether the value is set
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// IsZero reports whether the value is unset, for the omitzero option
+func (o Optional[T]) IsZero() bool {
+	return !o.set
+}
+
+// MarshalJSON encodes the value, or null when unset
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.set {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+// UnmarshalJSON sets the value
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	o.value, o.set = value, true
+	return nil
+}
+
+// Nullable is a column value that is unset, null or set to a value. Like
+// Optional, it is left out of request bodies when unset, so a partial update
+// can tell "set to NULL" from "leave unchanged".
+type Nullable[T any] struct {
+	value T
+	set   bool
+	valid bool
+}
+
+// NewNullable returns a Nullable set to value
+func NewNullable[T any](value T) Nullable[T] {
+	return Nullable[T]{value: value, set: true, valid: true}
+}
+
+// Null returns a Nullable set to null
+func Null[T any]() Nullable[T] {
+	return Nullable[T]{set: true}
+}
+
+// Get returns the value and whether it is set and not null
+func (n Nullable[T]) Get() (T, bool) {
+	return n.value, n.valid
+}
+
+// IsSet reports whether the value is set, possibly to null
+func (n Nullable[T]) IsSet() bool {
+	return n.set
+}
+
+// IsNull reports whether the value is set to null
+func (n Nullable[T]) IsNull() bool {
+	return n.set && !n.valid
+}
+
+// IsZero reports whether the value is unset, for the omitzero option
+func (n Nullable[T]) IsZero() bool {
+	return !n.set
+}
+
+// MarshalJSON encodes the value, or null when unset or null
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if !n.valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.value)
+}
+
+// UnmarshalJSON sets the value, or sets it to null
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*n = Null[T]()
+		return nil
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*n = NewNullable(value)
+	return nil
+}
+
+// settable is implemented by Optional and Nullable
+type settable interface {
+	IsSet() bool
+}
+
+var settableType = reflect.TypeFor[settable]()
+
+// errNoFieldsSet is returned by UpdateFields when there is nothing to update
+var errNoFieldsSet = errors.New("no fields set")
+
+// UpdateFields performs an UPDATE that only sends the columns of value, a
+// struct or map, that are set: Optional and Nullable fields or entries that
+// are unset are left out, whatever their tags, while other fields are sent as
+// encoding/json would.
+func (q *QueryBuilder[T]) UpdateFields(value interface{}, opts *UpdateOptions) *FilterBuilder[interface{}] {
+	body, err := setFields(value)
+	f := q.Update(body, opts)
+	if err != nil {
+		f.setErr(fmt.Errorf("update: %w", err))
+	}
+	return f
+}
+
+// setFields encodes value as a JSON object without its unset columns
+func setFields(value interface{}) (map[string]json.RawMessage, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("cannot update with %T: values must be a struct or map", value)
+	}
+
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	unset := func(name string, field reflect.Value) {
+		for field.Kind() == reflect.Interface && !field.IsNil() {
+			field = field.Elem()
+		}
+		if field.Type().Implements(settableType) && !field.Interface().(settable).IsSet() {
+			delete(fields, name)
+		}
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, column := range structColumns(v.Type(), jsonTags, false) {
+			if field, err := v.FieldByIndexErr(column.index); err == nil {
+				unset(column.name, field)
+			}
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			if key.Kind() == reflect.String {
+				unset(key.String(), v.MapIndex(key))
+			}
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, errNoFieldsSet
+	}
+	return fields, nil
+}
@@ -0,0 +1,91 @@
+package postgrest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type userPatch struct {
+	Username Optional[string] `json:"username,omitzero"`
+	Age      Optional[int]    `json:"age,omitzero"`
+	Status   Nullable[string] `json:"status,omitzero"`
+	Bio      Nullable[string] `json:"bio,omitzero"`
+}
+
+func TestOptional(t *testing.T) {
+	encoded, err := json.Marshal(userPatch{
+		Age:    Some(0),
+		Status: Null[string](),
+		Bio:    NewNullable("hi"),
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"age":0,"status":null,"bio":"hi"}`, string(encoded))
+
+	var decoded userPatch
+	assert.NoError(t, json.Unmarshal([]byte(`{"age":3,"status":null,"bio":"hi"}`), &decoded))
+	assert.False(t, decoded.Username.IsSet())
+	age, ok := decoded.Age.Get()
+	assert.True(t, ok)
+	assert.Equal(t, 3, age)
+	assert.True(t, decoded.Status.IsNull())
+	_, ok = decoded.Status.Get()
+	assert.False(t, ok)
+	bio, ok := decoded.Bio.Get()
+	assert.True(t, ok)
+	assert.Equal(t, "hi", bio)
+	assert.False(t, decoded.Bio.IsNull())
+}
+
+func TestQueryBuilder_UpdateFields(t *testing.T) {
+	c := createClient(t)
+	if !mockResponses {
+		t.Skip("requires mocked responses")
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var bodies []string
+	httpmock.RegisterRegexpResponder("PATCH", mockPath, func(req *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		return httpmock.NewStringResponse(204, ""), err
+	})
+
+	// Without omitzero, unset fields are still left out
+	type patch struct {
+		Username Optional[string] `json:"username"`
+		Status   Nullable[string] `json:"status"`
+		Note     string           `json:"note"`
+	}
+	_, err := c.From("users").
+		UpdateFields(patch{Status: Null[string]()}, nil).
+		Eq("id", 1).
+		Execute(context.Background())
+	assert.NoError(t, err)
+
+	_, err = c.From("users").
+		UpdateFields(map[string]interface{}{"username": Some("kiwicopple"), "status": Nullable[string]{}}, nil).
+		Eq("id", 1).
+		Execute(context.Background())
+	assert.NoError(t, err)
+
+	if assert.Len(t, bodies, 2) {
+		assert.JSONEq(t, `{"status":null,"note":""}`, bodies[0])
+		assert.JSONEq(t, `{"username":"kiwicopple"}`, bodies[1])
+	}
+
+	t.Run("NothingSet", func(t *testing.T) {
+		_, err := c.From("users").UpdateFields(&userPatch{}, nil).Eq("id", 1).Execute(context.Background())
+		assert.ErrorIs(t, err, errNoFieldsSet)
+
+		_, err = c.From("users").UpdateFields([]int{1}, nil).Eq("id", 1).Execute(context.Background())
+		assert.ErrorContains(t, err, "must be a struct or map")
+		assert.Len(t, bodies, 2)
+	})
+}
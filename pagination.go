@@ -0,0 +1,223 @@
+package postgrest
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// ParallelPagesOptions contains options for ParallelPages
+type ParallelPagesOptions struct {
+	PageSize    int    // rows per request, defaults to 1000
+	Concurrency int    // maximum number of requests in flight, defaults to 4
+	Count       string // "exact" or "planned", defaults to "exact"
+	Unordered   bool   // yield pages as they complete instead of in offset order
+}
+
+// ParallelPages counts the rows matched by the query with a HEAD request and
+// then fetches them in ranges of PageSize rows using a bounded pool of
+// concurrent requests. The first error cancels all outstanding requests and is
+// yielded as the final element. An existing Limit or Range on the query bounds
+// the rows fetched.
+//
+// Pages are only stable across requests when the query is ordered by a unique
+// column, so combine this with Order.
+func (b *Builder[T]) ParallelPages(ctx context.Context, opts *ParallelPagesOptions) iter.Seq2[T, error] {
+	if opts == nil {
+		opts = &ParallelPagesOptions{}
+	}
+
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = 1000
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	mode := opts.Count
+	if mode == "" {
+		mode = "exact"
+	}
+
+	return func(yield func(T, error) bool) {
+		var zero T
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		if b.method != "GET" && b.method != "HEAD" {
+			yield(zero, fmt.Errorf("parallel pages require a select query, got %s", b.method))
+			return
+		}
+
+		query := b.url.Query()
+		offset, _ := strconv.Atoi(query.Get("offset"))
+		limit := -1
+		if value := query.Get("limit"); value != "" {
+			limit, _ = strconv.Atoi(value)
+		}
+
+		count, err := b.Count(ctx, mode)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		total := int(count) - offset
+		if limit >= 0 && limit < total {
+			total = limit
+		}
+		pages := 0
+		if total > 0 {
+			pages = (total + pageSize - 1) / pageSize
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		defer func() {
+			cancel()
+			wg.Wait()
+		}()
+
+		type page struct {
+			index int
+			data  T
+			err   error
+		}
+
+		// A slot is taken before a page is requested and given back once the
+		// page has been consumed, so at most concurrency pages are in flight
+		// or waiting to be yielded.
+		slots := make(chan struct{}, concurrency)
+		unordered := make(chan page, concurrency)
+		ordered := make([]chan page, pages)
+		for i := range ordered {
+			ordered[i] = make(chan page, 1)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < pages; i++ {
+				select {
+				case slots <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+
+				size := min(pageSize, total-i*pageSize)
+				wg.Add(1)
+				go func(i, size int) {
+					defer wg.Done()
+					data, err := b.fetchRange(ctx, offset+i*pageSize, size)
+					p := page{index: i, data: data, err: err}
+					if opts.Unordered {
+						unordered <- p
+					} else {
+						ordered[i] <- p
+					}
+				}(i, size)
+			}
+		}()
+
+		lastFull := false
+		for n := 0; n < pages; n++ {
+			results := unordered
+			if !opts.Unordered {
+				results = ordered[n]
+			}
+
+			var p page
+			select {
+			case p = <-results:
+			case <-ctx.Done():
+				yield(zero, ctx.Err())
+				return
+			}
+			<-slots
+
+			if p.err != nil {
+				cancel()
+				yield(zero, p.err)
+				return
+			}
+			if p.index == pages-1 {
+				lastFull = rowCount(p.data) == pageSize
+			}
+			if !yield(p.data, nil) {
+				return
+			}
+		}
+
+		// A planned count is only an estimate, so keep reading sequentially
+		// while pages past the estimate come back full.
+		if mode == "exact" || limit >= 0 || (pages > 0 && !lastFull) {
+			return
+		}
+		for next := offset + pages*pageSize; ; next += pageSize {
+			data, err := b.fetchRange(ctx, next, pageSize)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			n := rowCount(data)
+			if n > 0 && !yield(data, nil) {
+				return
+			}
+			if n < pageSize {
+				return
+			}
+		}
+	}
+}
+
+// ParallelRows is like ParallelPages but yields the individual rows of each page
+func ParallelRows[T any](ctx context.Context, b *Builder[[]T], opts *ParallelPagesOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for page, err := range b.ParallelPages(ctx, opts) {
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, row := range page {
+				if !yield(row, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// fetchRange executes the builder's query for limit rows starting at offset
+func (b *Builder[T]) fetchRange(ctx context.Context, offset, limit int) (T, error) {
+	page := b.clone()
+	page.method = "GET"
+	setPreference(page.headers, "count", "")
+
+	query := page.url.Query()
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+	page.url.RawQuery = query.Encode()
+
+	response, err := page.Execute(ctx)
+	if err != nil {
+		return *new(T), err
+	}
+	if response.Error != nil {
+		return *new(T), response.Error
+	}
+	return response.Data, nil
+}
+
+// rowCount returns the number of rows in data if it is a slice
+func rowCount(data any) int {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice {
+		return 0
+	}
+	return v.Len()
+}
@@ -0,0 +1,109 @@
+package postgrest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// registerPagedUsers mocks a users table with total rows that honours the
+// offset and limit query parameters
+func registerPagedUsers(total int, failOffset int) {
+	httpmock.RegisterRegexpResponder("HEAD", mockPath, func(req *http.Request) (*http.Response, error) {
+		resp := httpmock.NewStringResponse(200, "")
+		resp.Header.Set("Content-Range", fmt.Sprintf("*/%d", total))
+		return resp, nil
+	})
+	httpmock.RegisterRegexpResponder("GET", mockPath, func(req *http.Request) (*http.Response, error) {
+		offset, _ := strconv.Atoi(req.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+		if offset == failOffset {
+			return httpmock.NewJsonResponse(500, map[string]interface{}{"message": "page failed"})
+		}
+		rows := []map[string]interface{}{}
+		for i := offset; i < offset+limit && i < total; i++ {
+			rows = append(rows, map[string]interface{}{"id": float64(i)})
+		}
+		return httpmock.NewJsonResponse(200, rows)
+	})
+}
+
+func TestBuilder_ParallelPages(t *testing.T) {
+	c := createClient(t)
+	if !mockResponses {
+		t.Skip("requires mocked responses")
+	}
+
+	t.Run("Ordered", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		registerPagedUsers(25, -1)
+
+		var ids []float64
+		query := c.From("users").Select("id", nil).Order("id", nil)
+		for row, err := range ParallelRows(context.Background(), query.Builder, &ParallelPagesOptions{PageSize: 10, Concurrency: 3}) {
+			assert.NoError(t, err)
+			ids = append(ids, row["id"].(float64))
+		}
+
+		assert.Len(t, ids, 25)
+		assert.True(t, sort.Float64sAreSorted(ids))
+		assert.Equal(t, 1, httpmock.GetCallCountInfo()["HEAD =~"+mockPath.String()])
+		assert.Equal(t, 3, httpmock.GetCallCountInfo()["GET =~"+mockPath.String()])
+	})
+
+	t.Run("Unordered", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		registerPagedUsers(25, -1)
+
+		seen := make(map[float64]bool)
+		query := c.From("users").Select("id", nil)
+		for page, err := range query.ParallelPages(context.Background(), &ParallelPagesOptions{PageSize: 4, Unordered: true}) {
+			assert.NoError(t, err)
+			for _, row := range page {
+				seen[row["id"].(float64)] = true
+			}
+		}
+		assert.Len(t, seen, 25)
+	})
+
+	t.Run("RespectsLimit", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		registerPagedUsers(25, -1)
+
+		var rows int
+		query := c.From("users").Select("id", nil).Range(5, 16, nil)
+		for page, err := range query.ParallelPages(context.Background(), &ParallelPagesOptions{PageSize: 5}) {
+			assert.NoError(t, err)
+			rows += len(page)
+		}
+		assert.Equal(t, 12, rows)
+	})
+
+	t.Run("StopsOnError", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		registerPagedUsers(25, 10)
+
+		var pages int
+		var lastErr error
+		query := c.From("users").Select("id", nil)
+		for _, err := range query.ParallelPages(context.Background(), &ParallelPagesOptions{PageSize: 5, Concurrency: 2}) {
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			pages++
+		}
+		assert.EqualError(t, lastErr, "page failed")
+		assert.Equal(t, 2, pages)
+	})
+}
@@ -0,0 +1,183 @@
+package pgtypes
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Array is a one-dimensional Postgres array such as text[], int[] or date[].
+// It decodes from the JSON array PostgREST returns as well as from an array
+// literal in a JSON string, and String formats the {a,b,c} literal used by
+// the array filters.
+type Array[T any] []T
+
+// String returns the Postgres array literal, quoting elements where needed
+func (a Array[T]) String() string {
+	parts := make([]string, len(a))
+	for i, v := range a {
+		parts[i] = quoteArrayElement(formatElement(v))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// MarshalText implements encoding.TextMarshaler with the array literal, which
+// lets the array filters format it
+func (a Array[T]) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// MarshalJSON encodes the array as a JSON array. A nil Array is encoded as
+// null.
+func (a Array[T]) MarshalJSON() ([]byte, error) {
+	if a == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal([]T(a))
+}
+
+// UnmarshalJSON decodes a JSON array or a string holding an array literal
+func (a *Array[T]) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*a = nil
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var literal string
+		if err := json.Unmarshal(data, &literal); err != nil {
+			return err
+		}
+		return a.UnmarshalText([]byte(literal))
+	}
+
+	var values []T
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*a = values
+	return nil
+}
+
+// UnmarshalText parses an array literal such as {a,"b c",NULL}. NULL
+// elements become the zero value of T.
+func (a *Array[T]) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return fmt.Errorf("invalid array %q", s)
+	}
+	s = s[1 : len(s)-1]
+
+	values := Array[T]{}
+	if s == "" {
+		*a = values
+		return nil
+	}
+
+	var current strings.Builder
+	quoted, inQuotes := false, false
+	flush := func() error {
+		var v T
+		text := current.String()
+		if quoted || text != "NULL" {
+			if err := parseElement(text, quoted, &v); err != nil {
+				return err
+			}
+		}
+		values = append(values, v)
+		current.Reset()
+		quoted = false
+		return nil
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			current.WriteByte(s[i])
+		case c == '"':
+			inQuotes = !inQuotes
+			quoted = true
+		case c == '{' && !inQuotes:
+			return fmt.Errorf("multidimensional arrays are not supported")
+		case c == ',' && !inQuotes:
+			if err := flush(); err != nil {
+				return err
+			}
+		default:
+			current.WriteByte(c)
+		}
+	}
+	if inQuotes {
+		return fmt.Errorf("invalid array %q: unterminated quote", string(text))
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	*a = values
+	return nil
+}
+
+// formatElement formats an array element as Postgres text
+func formatElement(v any) string {
+	switch v := v.(type) {
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(text)
+	case bool:
+		if v {
+			return "t"
+		}
+		return "f"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// quoteArrayElement double quotes an element containing characters with a
+// special meaning in array literals, or spelling NULL
+func quoteArrayElement(s string) string {
+	if s != "" && !strings.EqualFold(s, "null") && !strings.ContainsAny(s, "{},\"\\ \t\n") {
+		return s
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// parseElement converts the text of an array element into v. Quoted
+// elements and string targets take the text as is; other elements are
+// decoded first as a JSON value for numbers and booleans and then as a JSON
+// string for dates and other string encoded types.
+func parseElement(text string, quoted bool, v any) error {
+	if u, ok := v.(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(text))
+	}
+	if s, ok := v.(*string); ok {
+		*s = text
+		return nil
+	}
+	if !quoted {
+		raw := text
+		switch text {
+		case "t":
+			raw = "true"
+		case "f":
+			raw = "false"
+		}
+		if err := json.Unmarshal([]byte(raw), v); err == nil {
+			return nil
+		}
+	}
+	encoded, _ := json.Marshal(text)
+	if err := json.Unmarshal(encoded, v); err != nil {
+		return fmt.Errorf("invalid array element %q: %w", text, err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package pgtypes
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArray_JSON(t *testing.T) {
+	var row struct {
+		Strings Array[string]  `json:"list_of_strings"`
+		Dates   Array[Date]    `json:"list_of_datetimes"`
+		Ints    Array[int]     `json:"list_of_ints"`
+		Floats  Array[float64] `json:"list_of_floats"`
+		Literal Array[string]  `json:"literal"`
+		Missing Array[int]     `json:"missing"`
+	}
+	err := json.Unmarshal([]byte(`{
+		"list_of_strings": ["a", "b c"],
+		"list_of_datetimes": ["2024-01-01", "infinity"],
+		"list_of_ints": [1, 2, 3],
+		"list_of_floats": [1.5],
+		"literal": "{a,\"b,c\",NULL,\"NULL\",t}",
+		"missing": null
+	}`), &row)
+	assert.NoError(t, err)
+	assert.Equal(t, Array[string]{"a", "b c"}, row.Strings)
+	assert.Equal(t, Array[Date]{NewDate(2024, time.January, 1), {Infinity: PositiveInfinity}}, row.Dates)
+	assert.Equal(t, Array[int]{1, 2, 3}, row.Ints)
+	assert.Equal(t, Array[float64]{1.5}, row.Floats)
+	assert.Equal(t, Array[string]{"a", "b,c", "", "NULL", "t"}, row.Literal)
+	assert.Nil(t, row.Missing)
+
+	data, err := json.Marshal(row.Dates)
+	assert.NoError(t, err)
+	assert.Equal(t, `["2024-01-01","infinity"]`, string(data))
+}
+
+func TestArray_String(t *testing.T) {
+	assert.Equal(t, "{}", Array[int]{}.String())
+	assert.Equal(t, "{1,2,3}", Array[int]{1, 2, 3}.String())
+	assert.Equal(t, `{a,"b c","x,y","q\"t","",t,"null"}`, Array[string]{"a", "b c", "x,y", `q"t`, "", "t", "null"}.String())
+	assert.Equal(t, "{t,f}", Array[bool]{true, false}.String())
+	assert.Equal(t, "{2024-01-01,-infinity}", Array[Date]{NewDate(2024, time.January, 1), {Infinity: NegativeInfinity}}.String())
+}
+
+func TestArray_UnmarshalText(t *testing.T) {
+	var bools Array[bool]
+	assert.NoError(t, bools.UnmarshalText([]byte("{t,f,NULL}")))
+	assert.Equal(t, Array[bool]{true, false, false}, bools)
+
+	var ints Array[int]
+	assert.Error(t, ints.UnmarshalText([]byte("{{1,2},{3,4}}")))
+	assert.Error(t, ints.UnmarshalText([]byte("{a}")))
+	assert.Error(t, ints.UnmarshalText([]byte("1,2")))
+}
+
+func TestArray_UnmarshalText_Quoted(t *testing.T) {
+	var texts Array[string]
+	assert.NoError(t, texts.UnmarshalText([]byte(`{"null",NULL,"\"x\"",123,"t"}`)))
+	assert.Equal(t, Array[string]{"null", "", `"x"`, "123", "t"}, texts)
+
+	var values Array[any]
+	assert.NoError(t, values.UnmarshalText([]byte(`{"null",1,"2"}`)))
+	assert.Equal(t, Array[any]{"null", float64(1), "2"}, values)
+}
@@ -0,0 +1,9 @@
+// Package pgtypes provides Go types for Postgres values that encoding/json
+// can't represent faithfully on its own: arrays, dates and timestamps
+// including infinity, intervals, tsvector, money and numeric.
+//
+// Every type decodes from the JSON PostgREST returns, encodes to JSON
+// PostgREST accepts in request bodies, and formats with String as the
+// Postgres literal, so values can be passed straight to filters such as Eq
+// and In.
+package pgtypes
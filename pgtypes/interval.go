@@ -0,0 +1,211 @@
+package pgtypes
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Interval is a Postgres interval. Months and days are kept apart from the
+// time part because their length depends on the date they are added to.
+type Interval struct {
+	Months       int32
+	Days         int32
+	Microseconds int64
+}
+
+// String returns the interval in ISO 8601 form, e.g. P1Y2M3DT4H5M6.5S, which
+// Postgres accepts regardless of its IntervalStyle
+func (i Interval) String() string {
+	var sb strings.Builder
+	sb.WriteString("P")
+	if years := i.Months / 12; years != 0 {
+		fmt.Fprintf(&sb, "%dY", years)
+	}
+	if months := i.Months % 12; months != 0 {
+		fmt.Fprintf(&sb, "%dM", months)
+	}
+	if i.Days != 0 {
+		fmt.Fprintf(&sb, "%dD", i.Days)
+	}
+	if i.Microseconds != 0 || sb.Len() == 1 {
+		sb.WriteString("T")
+		micros := i.Microseconds
+		if hours := micros / 3600e6; hours != 0 {
+			fmt.Fprintf(&sb, "%dH", hours)
+		}
+		if minutes := micros % 3600e6 / 60e6; minutes != 0 {
+			fmt.Fprintf(&sb, "%dM", minutes)
+		}
+		if seconds := micros % 60e6; seconds != 0 || sb.Len() == 2 {
+			sb.WriteString(formatSeconds(seconds))
+			sb.WriteString("S")
+		}
+	}
+	return sb.String()
+}
+
+// formatSeconds formats micros as seconds, without trailing zeros
+func formatSeconds(micros int64) string {
+	s := strconv.FormatFloat(float64(micros)/1e6, 'f', 6, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// MarshalText implements encoding.TextMarshaler, so an Interval is encoded as
+// a JSON string
+func (i Interval) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the postgres
+// IntervalStyle PostgREST returns by default, such as "1 year 2 mons -3 days
+// 04:05:06.5", and ISO 8601.
+func (i *Interval) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	var parsed Interval
+	var err error
+	if strings.HasPrefix(s, "P") {
+		parsed, err = parseISOInterval(s)
+	} else {
+		parsed, err = parsePostgresInterval(s)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid interval %q: %w", s, err)
+	}
+	*i = parsed
+	return nil
+}
+
+// add adds value of unit to the interval, carrying fractional months and
+// days into the smaller fields like Postgres does
+func (i *Interval) add(value float64, unit string) error {
+	switch unit {
+	case "year", "years", "Y":
+		return i.add(value*12, "months")
+	case "mon", "mons", "month", "months":
+		whole := math.Trunc(value)
+		i.Months += int32(whole)
+		return i.add((value-whole)*30, "days")
+	case "week", "weeks", "W":
+		return i.add(value*7, "days")
+	case "day", "days", "D":
+		whole := math.Trunc(value)
+		i.Days += int32(whole)
+		i.Microseconds += int64(math.Round((value - whole) * 86400e6))
+	case "hour", "hours", "H":
+		i.Microseconds += int64(math.Round(value * 3600e6))
+	case "min", "mins", "minute", "minutes":
+		i.Microseconds += int64(math.Round(value * 60e6))
+	case "sec", "secs", "second", "seconds", "S":
+		i.Microseconds += int64(math.Round(value * 1e6))
+	default:
+		return fmt.Errorf("unknown unit %q", unit)
+	}
+	return nil
+}
+
+func parsePostgresInterval(s string) (Interval, error) {
+	var i Interval
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return i, fmt.Errorf("empty interval")
+	}
+
+	ago := false
+	for n := 0; n < len(fields); n++ {
+		field := fields[n]
+		switch {
+		case field == "@":
+		case field == "ago":
+			ago = true
+		case strings.Contains(field, ":"):
+			micros, err := parseClock(field)
+			if err != nil {
+				return i, err
+			}
+			i.Microseconds += micros
+		default:
+			value, err := strconv.ParseFloat(field, 64)
+			if err != nil || n+1 >= len(fields) {
+				return i, fmt.Errorf("unexpected %q", field)
+			}
+			n++
+			if err := i.add(value, fields[n]); err != nil {
+				return i, err
+			}
+		}
+	}
+	if ago {
+		i = Interval{Months: -i.Months, Days: -i.Days, Microseconds: -i.Microseconds}
+	}
+	return i, nil
+}
+
+// parseClock parses [+-]HH:MM[:SS[.ffffff]] into microseconds
+func parseClock(s string) (int64, error) {
+	sign := int64(1)
+	if strings.HasPrefix(s, "-") {
+		sign = -1
+		s = s[1:]
+	} else {
+		s = strings.TrimPrefix(s, "+")
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	hours, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	minutes, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	var seconds float64
+	if len(parts) == 3 {
+		if seconds, err = strconv.ParseFloat(parts[2], 64); err != nil {
+			return 0, fmt.Errorf("invalid time %q", s)
+		}
+	}
+	micros := hours*3600e6 + minutes*60e6 + int64(math.Round(seconds*1e6))
+	return sign * micros, nil
+}
+
+func parseISOInterval(s string) (Interval, error) {
+	var i Interval
+	inTime := false
+	number := ""
+	for _, c := range s[1:] {
+		switch {
+		case c == 'T':
+			inTime = true
+		case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+			number += string(c)
+		default:
+			value, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return i, fmt.Errorf("invalid number %q", number)
+			}
+			number = ""
+			unit := string(c)
+			if unit == "M" {
+				if inTime {
+					unit = "min"
+				} else {
+					unit = "mon"
+				}
+			}
+			if err := i.add(value, unit); err != nil {
+				return i, err
+			}
+		}
+	}
+	if number != "" {
+		return i, fmt.Errorf("missing unit after %q", number)
+	}
+	return i, nil
+}
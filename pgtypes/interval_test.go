@@ -0,0 +1,51 @@
+package pgtypes
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterval_UnmarshalText(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Interval
+	}{
+		{"1 year 2 mons 3 days 04:05:06.5", Interval{Months: 14, Days: 3, Microseconds: 14706500000}},
+		{"-1 days +02:03:00", Interval{Days: -1, Microseconds: 7380000000}},
+		{"00:00:00", Interval{}},
+		{"-00:00:01", Interval{Microseconds: -1000000}},
+		{"1.5 months", Interval{Months: 1, Days: 15}},
+		{"@ 2 hours ago", Interval{Microseconds: -7200000000}},
+		{"P1Y2M3DT4H5M6.5S", Interval{Months: 14, Days: 3, Microseconds: 14706500000}},
+		{"P-1DT-30M", Interval{Days: -1, Microseconds: -1800000000}},
+		{"P2W", Interval{Days: 14}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var i Interval
+			assert.NoError(t, i.UnmarshalText([]byte(tt.input)))
+			assert.Equal(t, tt.expected, i)
+		})
+	}
+
+	var i Interval
+	assert.Error(t, i.UnmarshalText([]byte("3 fortnights")))
+	assert.Error(t, i.UnmarshalText([]byte("P3")))
+}
+
+func TestInterval_String(t *testing.T) {
+	assert.Equal(t, "PT0S", Interval{}.String())
+	assert.Equal(t, "P1Y2M3DT4H5M6.5S", Interval{Months: 14, Days: 3, Microseconds: 14706500000}.String())
+	assert.Equal(t, "P-1DT-30M", Interval{Days: -1, Microseconds: -1800000000}.String())
+	assert.Equal(t, "PT0.000001S", Interval{Microseconds: 1}.String())
+
+	var decoded Interval
+	data, err := json.Marshal(Interval{Months: 1, Microseconds: 90000000})
+	assert.NoError(t, err)
+	assert.Equal(t, `"P1MT1M30S"`, string(data))
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, Interval{Months: 1, Microseconds: 90000000}, decoded)
+}
@@ -0,0 +1,216 @@
+package pgtypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Numeric is a Postgres numeric, or a bigint, kept as its exact decimal text
+// instead of being rounded through float64. It also holds the special values
+// NaN, Infinity and -Infinity.
+type Numeric string
+
+var numericRegexp = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// ParseNumeric validates s as a numeric literal and normalizes it to a valid
+// JSON number, so +5, .5 and 5. become 5, 0.5 and 5
+func ParseNumeric(s string) (Numeric, error) {
+	s = strings.TrimSpace(s)
+	if isSpecialNumeric(s) {
+		return Numeric(s), nil
+	}
+	if !numericRegexp.MatchString(s) {
+		return "", fmt.Errorf("invalid numeric %q", s)
+	}
+	return Numeric(normalizeNumeric(s)), nil
+}
+
+// normalizeNumeric drops the plus sign, a trailing decimal point and leading
+// zeros of a literal matching numericRegexp, and adds the zero before a
+// leading decimal point
+func normalizeNumeric(s string) string {
+	sign := ""
+	switch s[0] {
+	case '+':
+		s = s[1:]
+	case '-':
+		sign, s = "-", s[1:]
+	}
+	mantissa, exponent := s, ""
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa, exponent = s[:i], s[i:]
+	}
+	mantissa = strings.TrimSuffix(mantissa, ".")
+	integer, fraction := mantissa, ""
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		integer, fraction = mantissa[:i], mantissa[i:]
+	}
+	integer = strings.TrimLeft(integer, "0")
+	if integer == "" {
+		integer = "0"
+	}
+	return sign + integer + fraction + exponent
+}
+
+func isSpecialNumeric(s string) bool {
+	switch s {
+	case "NaN", "Infinity", "+Infinity", "-Infinity":
+		return true
+	default:
+		return false
+	}
+}
+
+// String returns the numeric literal
+func (n Numeric) String() string {
+	return string(n)
+}
+
+// Rat returns the value as an exact rational number. ok is false for the
+// special values and for an empty Numeric.
+func (n Numeric) Rat() (r *big.Rat, ok bool) {
+	return new(big.Rat).SetString(string(n))
+}
+
+// Int64 returns the value as an int64, failing if it is not an integer or
+// doesn't fit
+func (n Numeric) Int64() (int64, error) {
+	return strconv.ParseInt(string(n), 10, 64)
+}
+
+// Float64 returns the value rounded to the nearest float64
+func (n Numeric) Float64() (float64, error) {
+	return strconv.ParseFloat(string(n), 64)
+}
+
+// MarshalJSON encodes finite values as JSON numbers and the special values
+// as strings
+func (n Numeric) MarshalJSON() ([]byte, error) {
+	if n == "" {
+		return []byte("null"), nil
+	}
+	if isSpecialNumeric(string(n)) {
+		return json.Marshal(string(n))
+	}
+	if !numericRegexp.MatchString(string(n)) {
+		return nil, fmt.Errorf("invalid numeric %q", string(n))
+	}
+	return []byte(normalizeNumeric(string(n))), nil
+}
+
+// UnmarshalJSON decodes a JSON number or a string holding a numeric literal
+// without losing precision
+func (n *Numeric) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseNumeric(s)
+	if err != nil {
+		return err
+	}
+	*n = parsed
+	return nil
+}
+
+// Money is a Postgres money amount in cents. It assumes the two fractional
+// digits of the usual lc_monetary settings.
+type Money int64
+
+// String returns the amount as a plain decimal such as -12.34, which Postgres
+// accepts as money input
+func (m Money) String() string {
+	sign := ""
+	cents := int64(m)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// MarshalText implements encoding.TextMarshaler, so Money is encoded as a
+// JSON string
+func (m Money) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the
+// formatted output of Postgres, such as $1,234.56, -$5.00 or ($5.00), and
+// rejects characters other than digits, signs, separators and currency
+// symbols.
+func (m *Money) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+
+	var digits strings.Builder
+	fraction := -1
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			digits.WriteRune(c)
+			if fraction >= 0 {
+				fraction++
+			}
+		case c == '.':
+			if fraction >= 0 {
+				return fmt.Errorf("invalid money %q", string(text))
+			}
+			fraction = 0
+		case c == '-':
+			negative = true
+		case c == ',' || c == ' ' || unicode.Is(unicode.Sc, c):
+			// group separators and currency symbols
+		default:
+			return fmt.Errorf("invalid money %q", string(text))
+		}
+	}
+	if digits.Len() == 0 || fraction > 2 {
+		return fmt.Errorf("invalid money %q", string(text))
+	}
+	if fraction < 0 {
+		fraction = 0
+	}
+	digits.WriteString(strings.Repeat("0", 2-fraction))
+
+	cents, err := strconv.ParseInt(digits.String(), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid money %q: %w", string(text), err)
+	}
+	if negative {
+		cents = -cents
+	}
+	*m = Money(cents)
+	return nil
+}
+
+// UnmarshalJSON decodes the formatted string Postgres returns, or a JSON
+// number
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return m.UnmarshalText([]byte(s))
+	}
+	return m.UnmarshalText(data)
+}
@@ -0,0 +1,105 @@
+package pgtypes
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumeric_JSON(t *testing.T) {
+	var row struct {
+		Big     Numeric  `json:"big"`
+		Decimal Numeric  `json:"decimal"`
+		Text    Numeric  `json:"text"`
+		NaN     Numeric  `json:"nan"`
+		Missing *Numeric `json:"missing"`
+	}
+	err := json.Unmarshal([]byte(`{
+		"big": 9223372036854775807,
+		"decimal": 12345678901234567890.123456789,
+		"text": "0.1",
+		"nan": "NaN",
+		"missing": null
+	}`), &row)
+	assert.NoError(t, err)
+	assert.Equal(t, Numeric("9223372036854775807"), row.Big)
+	assert.Equal(t, Numeric("12345678901234567890.123456789"), row.Decimal)
+	assert.Equal(t, Numeric("NaN"), row.NaN)
+	assert.Nil(t, row.Missing)
+
+	n, err := row.Big.Int64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9223372036854775807), n)
+
+	r, ok := row.Text.Rat()
+	assert.True(t, ok)
+	assert.Equal(t, "1/10", r.String())
+	_, ok = row.NaN.Rat()
+	assert.False(t, ok)
+
+	data, err := json.Marshal(row)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"big":9223372036854775807,"decimal":12345678901234567890.123456789,"text":0.1,"nan":"NaN","missing":null}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`"1,5"`), &row.Text))
+	_, err = json.Marshal(Numeric("abc"))
+	assert.Error(t, err)
+}
+
+func TestParseNumeric_Normalizes(t *testing.T) {
+	tests := map[string]Numeric{
+		"+5":     "5",
+		".5":     "0.5",
+		"-.5":    "-0.5",
+		"5.":     "5",
+		"5.e3":   "5e3",
+		"007.10": "7.10",
+		"-0":     "-0",
+	}
+	for input, expected := range tests {
+		n, err := ParseNumeric(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, n, input)
+		assert.True(t, json.Valid([]byte(n)), input)
+	}
+
+	data, err := json.Marshal(Numeric("+.5"))
+	assert.NoError(t, err)
+	assert.Equal(t, "0.5", string(data))
+}
+
+func TestMoney(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Money
+	}{
+		{`"$1,234.56"`, 123456},
+		{`"-$5.00"`, -500},
+		{`"($5.10)"`, -510},
+		{`"12"`, 1200},
+		{`"0.5"`, 50},
+		{`7.25`, 725},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var m Money
+			assert.NoError(t, json.Unmarshal([]byte(tt.input), &m))
+			assert.Equal(t, tt.expected, m)
+		})
+	}
+
+	assert.Equal(t, "-12.05", Money(-1205).String())
+	data, err := json.Marshal(Money(123456))
+	assert.NoError(t, err)
+	assert.Equal(t, `"1234.56"`, string(data))
+
+	var m Money
+	assert.Error(t, m.UnmarshalText([]byte("$1.234")))
+	assert.Error(t, m.UnmarshalText([]byte("free")))
+	assert.Error(t, m.UnmarshalText([]byte("12abc")))
+	assert.Error(t, m.UnmarshalText([]byte("1e5")))
+	assert.NoError(t, m.UnmarshalText([]byte("€ 1,000.00")))
+	assert.Equal(t, Money(100000), m)
+}
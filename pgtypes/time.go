@@ -0,0 +1,188 @@
+package pgtypes
+
+import (
+	"fmt"
+	"time"
+)
+
+// Infinity marks a date or timestamp as one of the special values infinity
+// and -infinity
+type Infinity int8
+
+const (
+	Finite           Infinity = 0
+	PositiveInfinity Infinity = 1
+	NegativeInfinity Infinity = -1
+)
+
+// String returns the Postgres spelling of an infinite value, or "" for Finite
+func (i Infinity) String() string {
+	switch i {
+	case PositiveInfinity:
+		return "infinity"
+	case NegativeInfinity:
+		return "-infinity"
+	default:
+		return ""
+	}
+}
+
+func parseInfinity(s string) Infinity {
+	switch s {
+	case "infinity", "+infinity":
+		return PositiveInfinity
+	case "-infinity":
+		return NegativeInfinity
+	default:
+		return Finite
+	}
+}
+
+// Date is a Postgres date. Time holds midnight UTC of the day unless
+// Infinity is set.
+type Date struct {
+	Time     time.Time
+	Infinity Infinity
+}
+
+// NewDate returns the given calendar date
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// String returns the date as YYYY-MM-DD, infinity or -infinity
+func (d Date) String() string {
+	if d.Infinity != Finite {
+		return d.Infinity.String()
+	}
+	return d.Time.Format(dateLayout)
+}
+
+// MarshalText implements encoding.TextMarshaler, so a Date is encoded as a
+// JSON string
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Date) UnmarshalText(text []byte) error {
+	s := string(text)
+	if inf := parseInfinity(s); inf != Finite {
+		*d = Date{Infinity: inf}
+		return nil
+	}
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return fmt.Errorf("invalid date %q", s)
+	}
+	*d = Date{Time: t}
+	return nil
+}
+
+// Timestamp is a Postgres timestamp without time zone. Time holds the wall
+// clock reading in UTC unless Infinity is set.
+type Timestamp struct {
+	Time     time.Time
+	Infinity Infinity
+}
+
+// String returns the timestamp in ISO 8601 form without an offset, infinity
+// or -infinity
+func (t Timestamp) String() string {
+	if t.Infinity != Finite {
+		return t.Infinity.String()
+	}
+	return t.Time.Format(timestampLayout)
+}
+
+// MarshalText implements encoding.TextMarshaler, so a Timestamp is encoded as
+// a JSON string
+func (t Timestamp) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. An offset in text is
+// dropped, keeping the wall clock reading.
+func (t *Timestamp) UnmarshalText(text []byte) error {
+	s := string(text)
+	if inf := parseInfinity(s); inf != Finite {
+		*t = Timestamp{Infinity: inf}
+		return nil
+	}
+	parsed, err := parseTime(s, localLayouts, zonedLayouts)
+	if err != nil {
+		return err
+	}
+	y, mo, d := parsed.Date()
+	h, mi, sec := parsed.Clock()
+	*t = Timestamp{Time: time.Date(y, mo, d, h, mi, sec, parsed.Nanosecond(), time.UTC)}
+	return nil
+}
+
+// Timestamptz is a Postgres timestamp with time zone
+type Timestamptz struct {
+	Time     time.Time
+	Infinity Infinity
+}
+
+// String returns the timestamp in RFC 3339 form, infinity or -infinity
+func (t Timestamptz) String() string {
+	if t.Infinity != Finite {
+		return t.Infinity.String()
+	}
+	return t.Time.Format(time.RFC3339Nano)
+}
+
+// MarshalText implements encoding.TextMarshaler, so a Timestamptz is encoded
+// as a JSON string
+func (t Timestamptz) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Text without an offset
+// is read as UTC.
+func (t *Timestamptz) UnmarshalText(text []byte) error {
+	s := string(text)
+	if inf := parseInfinity(s); inf != Finite {
+		*t = Timestamptz{Infinity: inf}
+		return nil
+	}
+	parsed, err := parseTime(s, zonedLayouts, localLayouts)
+	if err != nil {
+		return err
+	}
+	*t = Timestamptz{Time: parsed}
+	return nil
+}
+
+const (
+	dateLayout      = "2006-01-02"
+	timestampLayout = "2006-01-02T15:04:05.999999999"
+)
+
+// Fractional seconds are accepted after the seconds field even though the
+// layouts don't mention them
+var (
+	zonedLayouts = []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05Z07",
+		"2006-01-02 15:04:05Z07:00",
+		"2006-01-02 15:04:05Z07",
+	}
+	localLayouts = []string{
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		dateLayout,
+	}
+)
+
+func parseTime(s string, layouts ...[]string) (time.Time, error) {
+	for _, group := range layouts {
+		for _, layout := range group {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
@@ -0,0 +1,63 @@
+package pgtypes
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimestamps_JSON(t *testing.T) {
+	var row struct {
+		Created    Timestamp   `json:"created"`
+		PosInf     Timestamp   `json:"pos_inf"`
+		NegInf     Timestamp   `json:"neg_inf"`
+		Updated    Timestamptz `json:"updated"`
+		Birthday   Date        `json:"birthday"`
+		Expiration Date        `json:"expiration"`
+		Missing    *Timestamp  `json:"missing"`
+	}
+	err := json.Unmarshal([]byte(`{
+		"created": "2024-03-01T10:20:30.123456",
+		"pos_inf": "infinity",
+		"neg_inf": "-infinity",
+		"updated": "2024-03-01T10:20:30+02:00",
+		"birthday": "1990-05-17",
+		"expiration": "infinity",
+		"missing": null
+	}`), &row)
+	assert.NoError(t, err)
+
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 20, 30, 123456000, time.UTC), row.Created.Time)
+	assert.Equal(t, PositiveInfinity, row.PosInf.Infinity)
+	assert.Equal(t, NegativeInfinity, row.NegInf.Infinity)
+	assert.True(t, row.Updated.Time.Equal(time.Date(2024, 3, 1, 8, 20, 30, 0, time.UTC)))
+	assert.Equal(t, NewDate(1990, time.May, 17), row.Birthday)
+	assert.Equal(t, PositiveInfinity, row.Expiration.Infinity)
+	assert.Nil(t, row.Missing)
+
+	data, err := json.Marshal(row)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"created": "2024-03-01T10:20:30.123456",
+		"pos_inf": "infinity",
+		"neg_inf": "-infinity",
+		"updated": "2024-03-01T10:20:30+02:00",
+		"birthday": "1990-05-17",
+		"expiration": "infinity",
+		"missing": null
+	}`, string(data))
+}
+
+func TestTimestamp_DropsOffset(t *testing.T) {
+	var ts Timestamp
+	assert.NoError(t, ts.UnmarshalText([]byte("2024-03-01 10:20:30+05")))
+	assert.Equal(t, "2024-03-01T10:20:30", ts.String())
+
+	var tz Timestamptz
+	assert.NoError(t, tz.UnmarshalText([]byte("2024-03-01 10:20:30")))
+	assert.Equal(t, "2024-03-01T10:20:30Z", tz.String())
+
+	assert.Error(t, ts.UnmarshalText([]byte("yesterday")))
+}
@@ -0,0 +1,128 @@
+package pgtypes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TSVector is a Postgres tsvector, a sorted list of distinct lexemes with
+// their optional positions
+type TSVector []Lexeme
+
+// Lexeme is a normalized word of a tsvector
+type Lexeme struct {
+	Word      string
+	Positions []LexemePosition
+}
+
+// LexemePosition is the position of a lexeme in the document, with the weight
+// A, B, C or D. D is the default and is left out of the text form.
+type LexemePosition struct {
+	Position int
+	Weight   byte
+}
+
+// String returns the tsvector literal, e.g. 'cat':3 'fat':2A
+func (v TSVector) String() string {
+	parts := make([]string, len(v))
+	for i, lexeme := range v {
+		var sb strings.Builder
+		sb.WriteString("'")
+		sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(lexeme.Word, `\`, `\\`), "'", "''"))
+		sb.WriteString("'")
+		for j, p := range lexeme.Positions {
+			if j == 0 {
+				sb.WriteString(":")
+			} else {
+				sb.WriteString(",")
+			}
+			sb.WriteString(strconv.Itoa(p.Position))
+			if p.Weight != 0 && p.Weight != 'D' {
+				sb.WriteByte(p.Weight)
+			}
+		}
+		parts[i] = sb.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// MarshalText implements encoding.TextMarshaler, so a TSVector is encoded as
+// a JSON string
+func (v TSVector) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (v *TSVector) UnmarshalText(text []byte) error {
+	s := string(text)
+	lexemes := TSVector{}
+	for i := 0; i < len(s); {
+		if s[i] == ' ' {
+			i++
+			continue
+		}
+
+		var word strings.Builder
+		if s[i] == '\'' {
+			i++
+			for ; ; i++ {
+				if i >= len(s) {
+					return fmt.Errorf("invalid tsvector %q: unterminated quote", s)
+				}
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				} else if s[i] == '\'' {
+					if i+1 < len(s) && s[i+1] == '\'' {
+						i++
+					} else {
+						i++
+						break
+					}
+				}
+				word.WriteByte(s[i])
+			}
+		} else {
+			for ; i < len(s) && s[i] != ' ' && s[i] != ':'; i++ {
+				word.WriteByte(s[i])
+			}
+		}
+
+		lexeme := Lexeme{Word: word.String()}
+		if i < len(s) && s[i] == ':' {
+			i++
+			for {
+				start := i
+				for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+					i++
+				}
+				position, err := strconv.Atoi(s[start:i])
+				if err != nil {
+					return fmt.Errorf("invalid tsvector %q: bad position", s)
+				}
+				p := LexemePosition{Position: position}
+				if i < len(s) && strings.IndexByte("ABCD", s[i]) >= 0 {
+					p.Weight = s[i]
+					i++
+				}
+				lexeme.Positions = append(lexeme.Positions, p)
+				if i >= len(s) || s[i] != ',' {
+					break
+				}
+				i++
+			}
+		}
+		lexemes = append(lexemes, lexeme)
+	}
+	*v = lexemes
+	return nil
+}
+
+// Words returns the lexemes without their positions
+func (v TSVector) Words() []string {
+	words := make([]string, len(v))
+	for i, lexeme := range v {
+		words[i] = lexeme.Word
+	}
+	return words
+}
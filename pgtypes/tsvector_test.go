@@ -0,0 +1,30 @@
+package pgtypes
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTSVector(t *testing.T) {
+	var row struct {
+		Search TSVector `json:"search"`
+	}
+	err := json.Unmarshal([]byte(`{"search": "'cat':3 'fat':2A,4 'it''s':1 'rat'"}`), &row)
+	assert.NoError(t, err)
+	assert.Equal(t, TSVector{
+		{Word: "cat", Positions: []LexemePosition{{Position: 3}}},
+		{Word: "fat", Positions: []LexemePosition{{Position: 2, Weight: 'A'}, {Position: 4}}},
+		{Word: "it's", Positions: []LexemePosition{{Position: 1}}},
+		{Word: "rat"},
+	}, row.Search)
+	assert.Equal(t, []string{"cat", "fat", "it's", "rat"}, row.Search.Words())
+	assert.Equal(t, "'cat':3 'fat':2A,4 'it''s':1 'rat'", row.Search.String())
+
+	var v TSVector
+	assert.NoError(t, v.UnmarshalText([]byte("")))
+	assert.Empty(t, v)
+	assert.Error(t, v.UnmarshalText([]byte("'open")))
+	assert.Error(t, v.UnmarshalText([]byte("'cat':x")))
+}
@@ -1,10 +1,10 @@
 package postgrest
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strings"
 )
 
@@ -41,6 +41,16 @@ func NewQueryBuilder[T any](client *Client, relation string) *QueryBuilder[T] {
 	}
 }
 
+// clone returns a copy of the query builder that can be modified without
+// affecting q
+func (q *QueryBuilder[T]) clone() *QueryBuilder[T] {
+	c := *q
+	u := *q.url
+	c.url = &u
+	c.headers = q.headers.Clone()
+	return &c
+}
+
 // SelectOptions contains options for Select
 type SelectOptions struct {
 	Head  bool
@@ -80,7 +90,7 @@ func (q *QueryBuilder[T]) Select(columns string, opts *SelectOptions) *FilterBui
 	q.url.RawQuery = query.Encode()
 
 	if opts.Count != "" && (opts.Count == "exact" || opts.Count == "planned" || opts.Count == "estimated") {
-		q.headers.Add("Prefer", fmt.Sprintf("count=%s", opts.Count))
+		setPreference(q.headers, "count", opts.Count)
 	}
 
 	builder := NewBuilder[[]T](q.client, method, q.url, &BuilderOptions{
@@ -95,6 +105,15 @@ func (q *QueryBuilder[T]) Select(columns string, opts *SelectOptions) *FilterBui
 type InsertOptions struct {
 	Count         string // "exact", "planned", or "estimated"
 	DefaultToNull bool
+	Returning     string // "minimal", "headers-only" or "representation"
+	// Columns restricts the inserted columns, ignoring other keys of the
+	// values. It defaults to the keys of the values encoded as JSON.
+	Columns []string
+}
+
+// isReturnPreference reports whether value is a valid return= preference
+func isReturnPreference(value string) bool {
+	return value == "minimal" || value == "headers-only" || value == "representation"
 }
 
 // Insert performs an INSERT into the table or view
@@ -113,32 +132,16 @@ func (q *QueryBuilder[T]) Insert(values interface{}, opts *InsertOptions) *Filte
 	}
 
 	if opts.Count != "" && (opts.Count == "exact" || opts.Count == "planned" || opts.Count == "estimated") {
-		headers.Add("Prefer", fmt.Sprintf("count=%s", opts.Count))
+		setPreference(headers, "count", opts.Count)
+	}
+	if isReturnPreference(opts.Returning) {
+		setPreference(headers, "return", opts.Returning)
 	}
 	if !opts.DefaultToNull {
-		headers.Add("Prefer", "missing=default")
+		setPreference(headers, "missing", "default")
 	}
 
-	// Handle array values to set columns parameter
-	valuesBytes, _ := json.Marshal(values)
-	var valuesArray []map[string]interface{}
-	if json.Unmarshal(valuesBytes, &valuesArray) == nil && len(valuesArray) > 0 {
-		columns := make(map[string]bool)
-		for _, row := range valuesArray {
-			for key := range row {
-				columns[key] = true
-			}
-		}
-		var uniqueColumns []string
-		for col := range columns {
-			uniqueColumns = append(uniqueColumns, fmt.Sprintf(`"%s"`, col))
-		}
-		if len(uniqueColumns) > 0 {
-			query := q.url.Query()
-			query.Set("columns", strings.Join(uniqueColumns, ","))
-			q.url.RawQuery = query.Encode()
-		}
-	}
+	q.setColumns(values, opts.Columns)
 
 	builder := NewBuilder[interface{}](q.client, method, q.url, &BuilderOptions{
 		Headers: headers,
@@ -155,6 +158,12 @@ type UpsertOptions struct {
 	IgnoreDuplicates bool
 	Count            string // "exact", "planned", or "estimated"
 	DefaultToNull    bool
+	Returning        string // "minimal", "headers-only" or "representation"
+	// Columns restricts the upserted columns like InsertOptions.Columns
+	Columns []string
+	// OnConflictColumns lists the columns of a composite unique constraint,
+	// as an alternative to a comma separated OnConflict
+	OnConflictColumns []string
 }
 
 // Upsert performs an UPSERT on the table or view
@@ -176,40 +185,31 @@ func (q *QueryBuilder[T]) Upsert(values interface{}, opts *UpsertOptions) *Filte
 	if opts.IgnoreDuplicates {
 		resolution = "ignore-duplicates"
 	}
-	headers.Add("Prefer", fmt.Sprintf("resolution=%s", resolution))
+	setPreference(headers, "resolution", resolution)
 
-	if opts.OnConflict != "" {
+	onConflict := opts.OnConflict
+	if len(opts.OnConflictColumns) > 0 {
+		if onConflict != "" {
+			onConflict += ","
+		}
+		onConflict += strings.Join(opts.OnConflictColumns, ",")
+	}
+	if onConflict != "" {
 		query := q.url.Query()
-		query.Set("on_conflict", opts.OnConflict)
+		query.Set("on_conflict", onConflict)
 		q.url.RawQuery = query.Encode()
 	}
 	if opts.Count != "" && (opts.Count == "exact" || opts.Count == "planned" || opts.Count == "estimated") {
-		headers.Add("Prefer", fmt.Sprintf("count=%s", opts.Count))
+		setPreference(headers, "count", opts.Count)
+	}
+	if isReturnPreference(opts.Returning) {
+		setPreference(headers, "return", opts.Returning)
 	}
 	if !opts.DefaultToNull {
-		headers.Add("Prefer", "missing=default")
+		setPreference(headers, "missing", "default")
 	}
 
-	// Handle array values to set columns parameter
-	valuesBytes, _ := json.Marshal(values)
-	var valuesArray []map[string]interface{}
-	if json.Unmarshal(valuesBytes, &valuesArray) == nil && len(valuesArray) > 0 {
-		columns := make(map[string]bool)
-		for _, row := range valuesArray {
-			for key := range row {
-				columns[key] = true
-			}
-		}
-		var uniqueColumns []string
-		for col := range columns {
-			uniqueColumns = append(uniqueColumns, fmt.Sprintf(`"%s"`, col))
-		}
-		if len(uniqueColumns) > 0 {
-			query := q.url.Query()
-			query.Set("columns", strings.Join(uniqueColumns, ","))
-			q.url.RawQuery = query.Encode()
-		}
-	}
+	q.setColumns(values, opts.Columns)
 
 	builder := NewBuilder[interface{}](q.client, method, q.url, &BuilderOptions{
 		Headers: headers,
@@ -220,9 +220,68 @@ func (q *QueryBuilder[T]) Upsert(values interface{}, opts *UpsertOptions) *Filte
 	return &FilterBuilder[interface{}]{Builder: builder}
 }
 
+// PutOptions contains options for Put
+type PutOptions struct {
+	Count     string // "exact", "planned", or "estimated"
+	Returning string // "minimal", "headers-only" or "representation"
+	// PrimaryKey lists the primary key columns, which default to the struct
+	// fields tagged `postgrest:"pk"`. It is required for maps.
+	PrimaryKey []string
+}
+
+// Put performs a single-row upsert with a PUT request: value is inserted, or
+// replaces the row with the same primary key. Unlike Upsert it is
+// idempotent. value is a struct or map holding every column of the row, and
+// its primary key columns are sent as eq filters.
+func (q *QueryBuilder[T]) Put(value interface{}, opts *PutOptions) *FilterBuilder[interface{}] {
+	if opts == nil {
+		opts = &PutOptions{}
+	}
+
+	method := "PUT"
+
+	headers := make(http.Header)
+	for key, values := range q.headers {
+		for _, val := range values {
+			headers.Add(key, val)
+		}
+	}
+
+	if opts.Count != "" && (opts.Count == "exact" || opts.Count == "planned" || opts.Count == "estimated") {
+		setPreference(headers, "count", opts.Count)
+	}
+	if isReturnPreference(opts.Returning) {
+		setPreference(headers, "return", opts.Returning)
+	}
+
+	builder := NewBuilder[interface{}](q.client, method, q.url, &BuilderOptions{
+		Headers: headers,
+		Schema:  q.schema,
+		Body:    value,
+	})
+	filter := &FilterBuilder[interface{}]{Builder: builder}
+
+	columns, values, err := primaryKeyFilters(value, opts.PrimaryKey)
+	if err != nil {
+		builder.setErr(fmt.Errorf("put: %w", err))
+		return filter
+	}
+	for i, column := range columns {
+		filter.Eq(column, values[i])
+	}
+	return filter
+}
+
 // UpdateOptions contains options for Update
 type UpdateOptions struct {
-	Count string // "exact", "planned", or "estimated"
+	Count     string // "exact", "planned", or "estimated"
+	Returning string // "minimal", "headers-only" or "representation"
+	// VersionColumn makes the update conditional on the column, such as a
+	// version number or updated_at timestamp, still being equal to Version.
+	// At most one row may be updated, and Execute returns ErrConflict when
+	// none was, so that concurrent writers can't overwrite each other.
+	VersionColumn string
+	Version       interface{}
 }
 
 // Update performs an UPDATE on the table or view
@@ -241,7 +300,17 @@ func (q *QueryBuilder[T]) Update(values interface{}, opts *UpdateOptions) *Filte
 	}
 
 	if opts.Count != "" && (opts.Count == "exact" || opts.Count == "planned" || opts.Count == "estimated") {
-		headers.Add("Prefer", fmt.Sprintf("count=%s", opts.Count))
+		setPreference(headers, "count", opts.Count)
+	}
+	if isReturnPreference(opts.Returning) {
+		setPreference(headers, "return", opts.Returning)
+	}
+
+	if opts.VersionColumn != "" {
+		// The exact count tells a conflict from a successful update
+		setPreference(headers, "count", "exact")
+		setPreference(headers, "handling", "strict")
+		setPreference(headers, "max-affected", "1")
 	}
 
 	builder := NewBuilder[interface{}](q.client, method, q.url, &BuilderOptions{
@@ -249,13 +318,28 @@ func (q *QueryBuilder[T]) Update(values interface{}, opts *UpdateOptions) *Filte
 		Schema:  q.schema,
 		Body:    values,
 	})
+	filter := &FilterBuilder[interface{}]{Builder: builder}
 
-	return &FilterBuilder[interface{}]{Builder: builder}
+	if opts.VersionColumn != "" {
+		builder.versioned = true
+		version := reflect.ValueOf(opts.Version)
+		for version.Kind() == reflect.Pointer && !version.IsNil() {
+			version = version.Elem()
+		}
+		if !version.IsValid() || version.Kind() == reflect.Pointer {
+			filter.Is(opts.VersionColumn, "null")
+		} else {
+			filter.Eq(opts.VersionColumn, formatTextValue(version.Interface()))
+		}
+	}
+
+	return filter
 }
 
 // DeleteOptions contains options for Delete
 type DeleteOptions struct {
-	Count string // "exact", "planned", or "estimated"
+	Count     string // "exact", "planned", or "estimated"
+	Returning string // "minimal", "headers-only" or "representation"
 }
 
 // Delete performs a DELETE on the table or view
@@ -274,7 +358,10 @@ func (q *QueryBuilder[T]) Delete(opts *DeleteOptions) *FilterBuilder[interface{}
 	}
 
 	if opts.Count != "" && (opts.Count == "exact" || opts.Count == "planned" || opts.Count == "estimated") {
-		headers.Add("Prefer", fmt.Sprintf("count=%s", opts.Count))
+		setPreference(headers, "count", opts.Count)
+	}
+	if isReturnPreference(opts.Returning) {
+		setPreference(headers, "return", opts.Returning)
 	}
 
 	builder := NewBuilder[interface{}](q.client, method, q.url, &BuilderOptions{
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
@@ -157,3 +158,201 @@ func TestQueryBuilder_Delete(t *testing.T) {
 		assert.NotNil(t, response)
 	}
 }
+
+func TestQueryBuilder_Insert_ResponseHeaders(t *testing.T) {
+	c := createClient(t)
+	if mockResponses {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterRegexpResponder("POST", mockPath, func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(201, "")
+			resp.Header.Set("Location", "/users?username=eq.new%2Cbie")
+			resp.Header.Set("Preference-Applied", "missing=default, tx=rollback")
+			resp.Header.Add("Preference-Applied", "count=exact")
+			return resp, nil
+		})
+	}
+
+	// The insert is rolled back when running against a real server
+	response, err := c.From("users").
+		Insert(map[string]interface{}{"username": "new,bie"}, &InsertOptions{Returning: "headers-only", Count: "exact"}).
+		Rollback().
+		Execute(context.Background())
+	assert.NoError(t, err)
+
+	assert.True(t, response.PreferenceApplied.Has("tx", "rollback"))
+	assert.False(t, response.PreferenceApplied.Has("handling", "strict"))
+	if mockResponses {
+		assert.Equal(t, "/users?username=eq.new%2Cbie", response.Header.Get("Location"))
+		assert.True(t, response.PreferenceApplied.Has("missing", "default"))
+		assert.True(t, response.PreferenceApplied.Has("count", "exact"))
+	}
+
+	filters, err := response.LocationFilters()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"username": "new,bie"}, filters)
+}
+
+func TestQueryBuilder_Returning(t *testing.T) {
+	c := createClient(t)
+	requests := recordRequests(c)
+	if mockResponses {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterRegexpResponder("POST", mockPath, httpmock.NewStringResponder(201, ""))
+		httpmock.RegisterRegexpResponder("PATCH", mockPath, httpmock.NewStringResponder(204, ""))
+		httpmock.RegisterRegexpResponder("DELETE", mockPath, httpmock.NewStringResponder(200, "[]"))
+	}
+
+	// The mutations are rolled back or match no rows when running against a
+	// real server
+	_, err := c.From("users").
+		Insert(map[string]interface{}{"username": "new"}, &InsertOptions{Returning: "headers-only", Count: "exact"}).
+		Rollback().
+		Execute(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"count=exact, missing=default, return=headers-only, tx=rollback"}, requests.prefer())
+
+	_, err = c.From("users").
+		Update(map[string]interface{}{"status": "OFFLINE"}, &UpdateOptions{Returning: "minimal"}).
+		Eq("username", "supabot").
+		Rollback().
+		MaxAffected(1).
+		Execute(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"handling=strict, max-affected=1, return=minimal, tx=rollback"}, requests.prefer())
+
+	// Select after a mutation overrides the return preference
+	_, err = c.From("users").
+		Delete(&DeleteOptions{Returning: "minimal"}).
+		Eq("username", "nobody").
+		Select("username").
+		Execute(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"return=representation"}, requests.prefer())
+}
+
+type putMembership struct {
+	OrgID  int    `json:"org_id" postgrest:"pk"`
+	UserID string `json:"user_id" postgrest:"pk"`
+	Role   string `json:"role"`
+}
+
+func TestQueryBuilder_Put(t *testing.T) {
+	c := createClient(t)
+	if !mockResponses {
+		t.Skip("requires mocked responses")
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var queries []string
+	httpmock.RegisterRegexpResponder("PUT", mockPath, func(req *http.Request) (*http.Response, error) {
+		queries = append(queries, req.URL.RawQuery)
+		return httpmock.NewStringResponse(200, ""), nil
+	})
+
+	_, err := c.From("memberships").
+		Put(&putMembership{OrgID: 1, UserID: "a b", Role: "admin"}, &PutOptions{Returning: "minimal"}).
+		Execute(context.Background())
+	assert.NoError(t, err)
+
+	_, err = c.From("users").
+		Put(map[string]interface{}{"id": 7, "username": "kiwicopple"}, &PutOptions{PrimaryKey: []string{"id"}}).
+		Execute(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"org_id=eq.1&user_id=eq.a+b", "id=eq.7"}, queries)
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := c.From("users").Put(map[string]interface{}{"id": 7}, nil).Execute(context.Background())
+		assert.ErrorContains(t, err, "no primary key columns")
+
+		_, err = c.From("users").Put(putMembership{}, &PutOptions{PrimaryKey: []string{"id"}}).Execute(context.Background())
+		assert.ErrorContains(t, err, "primary key column id is not a field")
+
+		_, err = c.From("users").Put([]putMembership{{}}, nil).Execute(context.Background())
+		assert.ErrorContains(t, err, "rows must be structs or maps")
+		assert.Len(t, queries, 2)
+	})
+}
+
+func TestQueryBuilder_Upsert_OnConflictColumns(t *testing.T) {
+	c := createClient(t)
+	if !mockResponses {
+		t.Skip("requires mocked responses")
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var onConflict string
+	httpmock.RegisterRegexpResponder("POST", mockPath, func(req *http.Request) (*http.Response, error) {
+		onConflict = req.URL.Query().Get("on_conflict")
+		return httpmock.NewStringResponse(201, ""), nil
+	})
+
+	_, err := c.From("memberships").
+		Upsert(putMembership{OrgID: 1, UserID: "a"}, &UpsertOptions{OnConflictColumns: []string{"org_id", "user_id"}}).
+		Execute(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "org_id,user_id", onConflict)
+}
+
+func TestQueryBuilder_Update_Versioned(t *testing.T) {
+	c := createClient(t)
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if mockResponses {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterRegexpResponder("PATCH", mockPath, func(req *http.Request) (*http.Response, error) {
+			prefer := parsePreferences(req.Header.Values("Prefer"))
+			assert.Equal(t, "exact", prefer["count"])
+			assert.Equal(t, "strict", prefer["handling"])
+			assert.Equal(t, "1", prefer["max-affected"])
+
+			resp := httpmock.NewStringResponse(204, "")
+			if req.URL.Query().Get("updated_at") == "eq."+formatTextValue(updatedAt) {
+				resp.Header.Set("Content-Range", "*/1")
+			} else {
+				resp.Header.Set("Content-Range", "*/0")
+			}
+			return resp, nil
+		})
+	} else {
+		var rows []struct {
+			UpdatedAt string `json:"updated_at"`
+		}
+		_, err := c.From("users").Select("updated_at", nil).Eq("username", "supabot").ExecuteTo(context.Background(), &rows)
+		if assert.NoError(t, err) && assert.Len(t, rows, 1) {
+			updatedAt, err = time.Parse("2006-01-02T15:04:05.999999999", rows[0].UpdatedAt)
+			assert.NoError(t, err)
+		}
+	}
+
+	// The update leaves the row as it was when running against a real server
+	update := func(version interface{}) error {
+		response, err := c.From("users").
+			Update(map[string]interface{}{"status": "ONLINE"}, &UpdateOptions{VersionColumn: "updated_at", Version: version}).
+			Eq("username", "supabot").
+			Execute(context.Background())
+		if err != nil {
+			assert.Nil(t, response)
+		}
+		return err
+	}
+	assert.NoError(t, update(updatedAt))
+	assert.NoError(t, update(&updatedAt))
+	assert.ErrorIs(t, update(updatedAt.Add(-time.Second)), ErrConflict)
+	assert.ErrorIs(t, update(nil), ErrConflict)
+
+	var rows []map[string]interface{}
+	count, err := c.From("users").
+		Update(map[string]interface{}{"status": "ONLINE"}, &UpdateOptions{VersionColumn: "updated_at"}).
+		Eq("username", "supabot").
+		ExecuteTo(context.Background(), &rows)
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Nil(t, count)
+}
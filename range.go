@@ -0,0 +1,305 @@
+package postgrest
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/supabase-community/postgrest-go/pgtypes"
+)
+
+// Bound is one end of a Range
+type Bound[T any] struct {
+	Value     T
+	Inclusive bool
+	// Infinite marks an unbounded end, in which case Value is ignored
+	Infinite bool
+	// Infinity marks a time.Time bound holding infinity or -infinity, in
+	// which case Value is ignored. Unlike an unbounded end, such a bound is a
+	// value, which may be inclusive.
+	Infinity pgtypes.Infinity
+}
+
+// Inclusive returns a bound that includes value
+func Inclusive[T any](value T) Bound[T] {
+	return Bound[T]{Value: value, Inclusive: true}
+}
+
+// Exclusive returns a bound that excludes value
+func Exclusive[T any](value T) Bound[T] {
+	return Bound[T]{Value: value}
+}
+
+// Unbounded returns an infinite bound
+func Unbounded[T any]() Bound[T] {
+	return Bound[T]{Infinite: true}
+}
+
+// Range is a Postgres range value such as int4range, int8range, numrange,
+// daterange, tsrange or tstzrange. It encodes to the range literal used by
+// range filters, e.g. [1,10), and decodes from the same literal in JSON
+// responses.
+//
+// Elements are formatted and parsed as integers, floats, strings, time.Time
+// or any type implementing encoding.TextMarshaler and
+// encoding.TextUnmarshaler. Use string for numeric ranges that must not lose
+// precision.
+type Range[T any] struct {
+	Lower Bound[T]
+	Upper Bound[T]
+	Empty bool
+}
+
+// NewRange returns the range between lower and upper
+func NewRange[T any](lower, upper Bound[T]) Range[T] {
+	return Range[T]{Lower: lower, Upper: upper}
+}
+
+// EmptyRange returns the empty range
+func EmptyRange[T any]() Range[T] {
+	return Range[T]{Empty: true}
+}
+
+// String returns the Postgres range literal
+func (r Range[T]) String() string {
+	if r.Empty {
+		return "empty"
+	}
+
+	var sb strings.Builder
+	if r.Lower.Inclusive && !r.Lower.Infinite {
+		sb.WriteString("[")
+	} else {
+		sb.WriteString("(")
+	}
+	if !r.Lower.Infinite {
+		sb.WriteString(r.Lower.format())
+	}
+	sb.WriteString(",")
+	if !r.Upper.Infinite {
+		sb.WriteString(r.Upper.format())
+	}
+	if r.Upper.Inclusive && !r.Upper.Infinite {
+		sb.WriteString("]")
+	} else {
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
+// format returns the quoted text of a finite bound
+func (b Bound[T]) format() string {
+	if b.Infinity != pgtypes.Finite {
+		return b.Infinity.String()
+	}
+	return quoteRangeElement(formatTextValue(b.Value))
+}
+
+// postgresRange is implemented by Range for the filters that also accept
+// arrays and json, which would otherwise marshal it as a JSON string
+type postgresRange interface {
+	rangeLiteral() string
+}
+
+func (r Range[T]) rangeLiteral() string {
+	return r.String()
+}
+
+// MarshalJSON encodes the range as its literal in a JSON string
+func (r Range[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON decodes a range literal from a JSON string. null leaves the
+// range unchanged.
+func (r *Range[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var literal string
+	if err := json.Unmarshal(data, &literal); err != nil {
+		return fmt.Errorf("range must be a JSON string: %w", err)
+	}
+	parsed, err := ParseRange[T](literal)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// ParseRange parses a Postgres range literal such as [1,10), (,5] or empty
+func ParseRange[T any](literal string) (Range[T], error) {
+	var r Range[T]
+	literal = strings.TrimSpace(literal)
+	if strings.EqualFold(literal, "empty") {
+		r.Empty = true
+		return r, nil
+	}
+	if len(literal) < 3 {
+		return r, fmt.Errorf("invalid range %q", literal)
+	}
+
+	open, close := literal[0], literal[len(literal)-1]
+	if (open != '[' && open != '(') || (close != ']' && close != ')') {
+		return r, fmt.Errorf("invalid range %q", literal)
+	}
+
+	lower, upper, err := splitRangeElements(literal[1 : len(literal)-1])
+	if err != nil {
+		return r, fmt.Errorf("invalid range %q: %w", literal, err)
+	}
+	if r.Lower, err = parseBound[T](lower, open == '['); err != nil {
+		return r, fmt.Errorf("invalid range %q: %w", literal, err)
+	}
+	if r.Upper, err = parseBound[T](upper, close == ']'); err != nil {
+		return r, fmt.Errorf("invalid range %q: %w", literal, err)
+	}
+	return r, nil
+}
+
+// rangeElement is a bound of a range literal before it is converted to T
+type rangeElement struct {
+	text   string
+	quoted bool
+}
+
+// splitRangeElements splits the inside of a range literal at its top-level
+// comma, removing quotes and escapes
+func splitRangeElements(s string) (rangeElement, rangeElement, error) {
+	var elements []rangeElement
+	var current strings.Builder
+	quoted, inQuotes := false, false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			current.WriteByte(s[i])
+		case c == '"' && inQuotes && i+1 < len(s) && s[i+1] == '"':
+			i++
+			current.WriteByte('"')
+		case c == '"':
+			inQuotes = !inQuotes
+			quoted = true
+		case c == ',' && !inQuotes:
+			elements = append(elements, rangeElement{text: current.String(), quoted: quoted})
+			current.Reset()
+			quoted = false
+		default:
+			current.WriteByte(c)
+		}
+	}
+	elements = append(elements, rangeElement{text: current.String(), quoted: quoted})
+
+	if inQuotes || len(elements) != 2 {
+		return rangeElement{}, rangeElement{}, fmt.Errorf("expected two bounds")
+	}
+	return elements[0], elements[1], nil
+}
+
+func parseBound[T any](element rangeElement, inclusive bool) (Bound[T], error) {
+	if element.text == "" && !element.quoted {
+		return Unbounded[T](), nil
+	}
+
+	var value T
+	if _, ok := any(value).(time.Time); ok && !element.quoted {
+		var ts pgtypes.Timestamptz
+		if err := ts.UnmarshalText([]byte(element.text)); err == nil && ts.Infinity != pgtypes.Finite {
+			return Bound[T]{Inclusive: inclusive, Infinity: ts.Infinity}, nil
+		}
+	}
+	if err := parseTextValue(element.text, &value); err != nil {
+		return Bound[T]{}, err
+	}
+	return Bound[T]{Value: value, Inclusive: inclusive}, nil
+}
+
+// formatTextValue formats a range bound or filter value
+func formatTextValue(value any) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(text)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// quoteRangeElement double quotes a bound containing characters with a
+// special meaning in range literals
+func quoteRangeElement(s string) string {
+	if s != "" && !strings.ContainsAny(s, ",()[]\"\\ ") {
+		return s
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// parseTime parses a date or timestamp in any of the formats Postgres emits,
+// like pgtypes.Timestamptz. time.Time can't hold infinity or -infinity.
+func parseTime(s string) (time.Time, error) {
+	var ts pgtypes.Timestamptz
+	if err := ts.UnmarshalText([]byte(s)); err != nil {
+		return time.Time{}, err
+	}
+	if ts.Infinity != pgtypes.Finite {
+		return time.Time{}, fmt.Errorf("time.Time can't hold %s", ts.Infinity)
+	}
+	return ts.Time, nil
+}
+
+// parseTextValue parses the text form of a range bound or CSV field into
+// value
+func parseTextValue(s string, value any) error {
+	switch v := value.(type) {
+	case *time.Time:
+		t, err := parseTime(s)
+		if err != nil {
+			return err
+		}
+		*v = t
+		return nil
+	case encoding.TextUnmarshaler:
+		return v.UnmarshalText([]byte(s))
+	}
+
+	rv := reflect.ValueOf(value).Elem()
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", rv.Type())
+	}
+	return nil
+}
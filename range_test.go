@@ -0,0 +1,149 @@
+package postgrest
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supabase-community/postgrest-go/pgtypes"
+)
+
+func TestRange_String(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		value    interface{ String() string }
+		expected string
+	}{
+		{"Half open", NewRange(Inclusive(1), Exclusive(10)), "[1,10)"},
+		{"Closed", NewRange(Inclusive(1.5), Inclusive(2.5)), "[1.5,2.5]"},
+		{"Unbounded lower", NewRange(Unbounded[int](), Inclusive(5)), "(,5]"},
+		{"Unbounded upper", NewRange(Exclusive(int64(3)), Unbounded[int64]()), "(3,)"},
+		{"Empty", EmptyRange[int](), "empty"},
+		{"Timestamps", NewRange(Inclusive(day(1)), Exclusive(day(2))), "[2024-01-01T00:00:00Z,2024-01-02T00:00:00Z)"},
+		{"Quoted strings", NewRange(Inclusive("a b"), Exclusive(`c"d`)), `["a b","c\"d")`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.value.String())
+		})
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	t.Run("Integers", func(t *testing.T) {
+		r, err := ParseRange[int]("[1,10)")
+		assert.NoError(t, err)
+		assert.Equal(t, NewRange(Inclusive(1), Exclusive(10)), r)
+	})
+
+	t.Run("Infinite bounds", func(t *testing.T) {
+		r, err := ParseRange[int]("(,5]")
+		assert.NoError(t, err)
+		assert.True(t, r.Lower.Infinite)
+		assert.Equal(t, Inclusive(5), r.Upper)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		r, err := ParseRange[int]("empty")
+		assert.NoError(t, err)
+		assert.True(t, r.Empty)
+	})
+
+	t.Run("Quoted timestamps", func(t *testing.T) {
+		r, err := ParseRange[time.Time](`["2024-01-01 10:00:00+00","2024-01-01 12:30:00.5+02")`)
+		assert.NoError(t, err)
+		assert.True(t, r.Lower.Value.Equal(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)))
+		assert.True(t, r.Upper.Value.Equal(time.Date(2024, 1, 1, 10, 30, 0, 500000000, time.UTC)))
+	})
+
+	t.Run("Dates and infinity", func(t *testing.T) {
+		r, err := ParseRange[time.Time]("[2024-01-01,infinity)")
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), r.Lower.Value)
+		assert.False(t, r.Upper.Infinite)
+		assert.Equal(t, pgtypes.PositiveInfinity, r.Upper.Infinity)
+		assert.Equal(t, "[2024-01-01T00:00:00Z,infinity)", r.String())
+
+		r, err = ParseRange[time.Time]("[-infinity,2024-01-01)")
+		assert.NoError(t, err)
+		assert.True(t, r.Lower.Inclusive)
+		assert.Equal(t, pgtypes.NegativeInfinity, r.Lower.Infinity)
+		assert.Equal(t, "[-infinity,2024-01-01T00:00:00Z)", r.String())
+
+		r, err = ParseRange[time.Time]("(,2024-01-01)")
+		assert.NoError(t, err)
+		assert.True(t, r.Lower.Infinite)
+		assert.Equal(t, "(,2024-01-01T00:00:00Z)", r.String())
+	})
+
+	t.Run("Quoted string elements", func(t *testing.T) {
+		r, err := ParseRange[string](`["a,b","c\"d"]`)
+		assert.NoError(t, err)
+		assert.Equal(t, "a,b", r.Lower.Value)
+		assert.Equal(t, `c"d`, r.Upper.Value)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, literal := range []string{"", "1,10", "[1,2,3)", "[a,10)"} {
+			_, err := ParseRange[int](literal)
+			assert.Error(t, err, literal)
+		}
+	})
+}
+
+func TestRange_JSON(t *testing.T) {
+	var user struct {
+		AgeRange Range[int]       `json:"age_range"`
+		Missing  *Range[int]      `json:"missing"`
+		Active   Range[time.Time] `json:"active"`
+		Price    Range[string]    `json:"price"`
+		Others   []Range[float64] `json:"others"`
+	}
+	err := json.Unmarshal([]byte(`{
+		"age_range": "[18,65)",
+		"missing": null,
+		"active": "[\"2024-01-01 00:00:00+00\",)",
+		"price": "[1.10,99.999]",
+		"others": ["empty"]
+	}`), &user)
+	assert.NoError(t, err)
+	assert.Equal(t, NewRange(Inclusive(18), Exclusive(65)), user.AgeRange)
+	assert.Nil(t, user.Missing)
+	assert.True(t, user.Active.Upper.Infinite)
+	assert.Equal(t, "99.999", user.Price.Upper.Value)
+	assert.True(t, user.Others[0].Empty)
+
+	data, err := json.Marshal(user.AgeRange)
+	assert.NoError(t, err)
+	assert.Equal(t, `"[18,65)"`, string(data))
+}
+
+func TestRange_Filters(t *testing.T) {
+	client := NewClient("http://localhost:3000", "", nil)
+	ages := NewRange(Inclusive(18), Exclusive(65))
+
+	f := client.From("users").Select("*", nil).
+		RangeGt("a", ages).
+		RangeGte("b", ages).
+		RangeLt("c", ages).
+		RangeLte("d", ages).
+		RangeAdjacent("e", "[1,2)").
+		Contains("f", ages).
+		ContainedBy("g", ages).
+		Overlaps("h", NewRange(Unbounded[int](), Inclusive(5)))
+
+	query, _ := url.ParseQuery(f.url.RawQuery)
+	assert.Equal(t, "sr.[18,65)", query.Get("a"))
+	assert.Equal(t, "nxl.[18,65)", query.Get("b"))
+	assert.Equal(t, "sl.[18,65)", query.Get("c"))
+	assert.Equal(t, "nxr.[18,65)", query.Get("d"))
+	assert.Equal(t, "adj.[1,2)", query.Get("e"))
+	assert.Equal(t, "cs.[18,65)", query.Get("f"))
+	assert.Equal(t, "cd.[18,65)", query.Get("g"))
+	assert.Equal(t, "ov.(,5]", query.Get("h"))
+}
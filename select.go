@@ -0,0 +1,351 @@
+package postgrest
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SelectItem is an entry of a structured select list, built with Col,
+// CountAll or Embed and rendered with Columns
+type SelectItem interface {
+	selectString() string
+}
+
+// Column is a column of a select list that can be aliased, cast and aggregated
+type Column struct {
+	name       string
+	alias      string
+	cast       string
+	aggregate  string
+	resultCast string
+}
+
+// Col selects the named column, or every column for "*"
+func Col(name string) Column {
+	return Column{name: name}
+}
+
+// CountAll selects count() of the rows in each group
+func CountAll() Column {
+	return Column{aggregate: "count"}
+}
+
+// As renames the column in the result
+func (c Column) As(alias string) Column {
+	c.alias = alias
+	return c
+}
+
+// Cast casts the column to typ. Called before an aggregate it casts the
+// aggregated input (amount::int.sum()), called after it casts the result
+// (amount.sum()::text).
+func (c Column) Cast(typ string) Column {
+	if c.aggregate != "" {
+		c.resultCast = typ
+	} else {
+		c.cast = typ
+	}
+	return c
+}
+
+// Sum aggregates the column with sum(). Other selected columns become the
+// grouping columns. Aggregates require db-aggregates-enabled in PostgREST.
+func (c Column) Sum() Column {
+	c.aggregate = "sum"
+	return c
+}
+
+// Avg aggregates the column with avg()
+func (c Column) Avg() Column {
+	c.aggregate = "avg"
+	return c
+}
+
+// Min aggregates the column with min()
+func (c Column) Min() Column {
+	c.aggregate = "min"
+	return c
+}
+
+// Max aggregates the column with max()
+func (c Column) Max() Column {
+	c.aggregate = "max"
+	return c
+}
+
+// Count aggregates the column with count(), counting its non-null values
+func (c Column) Count() Column {
+	c.aggregate = "count"
+	return c
+}
+
+func (c Column) selectString() string {
+	var sb strings.Builder
+	if c.alias != "" {
+		sb.WriteString(c.alias)
+		sb.WriteString(":")
+	}
+	sb.WriteString(c.name)
+	if c.cast != "" {
+		sb.WriteString("::")
+		sb.WriteString(c.cast)
+	}
+	if c.aggregate != "" {
+		if c.name != "" {
+			sb.WriteString(".")
+		}
+		sb.WriteString(c.aggregate)
+		sb.WriteString("()")
+		if c.resultCast != "" {
+			sb.WriteString("::")
+			sb.WriteString(c.resultCast)
+		}
+	}
+	return sb.String()
+}
+
+// String returns the column as it appears in a select string
+func (c Column) String() string {
+	return c.selectString()
+}
+
+// EmbeddedResource is a related table or view embedded in a select list.
+// Filters, ordering and limits set on it apply to the embedded rows only and
+// are sent as parameters prefixed with the resource's alias or name.
+type EmbeddedResource struct {
+	relation string
+	alias    string
+	hint     string
+	join     string
+	spread   bool
+	items    []SelectItem
+	params   []embedParam
+	order    []string
+	// err is an invalid argument, returned when the query embedding the
+	// resource is executed
+	err error
+}
+
+// embedParam is a query parameter scoped to an embedded resource
+type embedParam struct {
+	key   string
+	value string
+}
+
+// Embed embeds the related relation with the given select items, or all of
+// its columns when none are given. Aggregates inside items are grouped per
+// parent row.
+func Embed(relation string, items ...SelectItem) *EmbeddedResource {
+	return &EmbeddedResource{relation: relation, items: items}
+}
+
+// As renames the embedded resource in the result
+func (e *EmbeddedResource) As(alias string) *EmbeddedResource {
+	e.alias = alias
+	return e
+}
+
+// Hint disambiguates the relationship by foreign key constraint or column
+// name when the tables are related in more than one way
+func (e *EmbeddedResource) Hint(fk string) *EmbeddedResource {
+	e.hint = fk
+	return e
+}
+
+// Inner embeds with an inner join, so parent rows without matching embedded
+// rows, including those removed by the embed's filters, are excluded
+func (e *EmbeddedResource) Inner() *EmbeddedResource {
+	e.join = "inner"
+	return e
+}
+
+// Left embeds with a left join, the default
+func (e *EmbeddedResource) Left() *EmbeddedResource {
+	e.join = "left"
+	return e
+}
+
+// Spread lifts the embedded columns into the parent object. Only to-one
+// relationships can be spread.
+func (e *EmbeddedResource) Spread() *EmbeddedResource {
+	e.spread = true
+	return e
+}
+
+// Filter adds a filtering operator on a column of the embedded resource
+func (e *EmbeddedResource) Filter(column string, operator Operator, value string) *EmbeddedResource {
+	if !isOperator(operator) {
+		if e.err == nil {
+			e.err = invalidOperatorError(operator)
+		}
+		return e
+	}
+	return e.addParam(column, fmt.Sprintf("%s.%s", operator, value))
+}
+
+// Eq matches only embedded rows where column is equal to value
+func (e *EmbeddedResource) Eq(column string, value interface{}) *EmbeddedResource {
+	return e.addParam(column, fmt.Sprintf("eq.%v", value))
+}
+
+// Neq matches only embedded rows where column is not equal to value
+func (e *EmbeddedResource) Neq(column string, value interface{}) *EmbeddedResource {
+	return e.addParam(column, fmt.Sprintf("neq.%v", value))
+}
+
+// Gt matches only embedded rows where column is greater than value
+func (e *EmbeddedResource) Gt(column string, value interface{}) *EmbeddedResource {
+	return e.addParam(column, fmt.Sprintf("gt.%v", value))
+}
+
+// Gte matches only embedded rows where column is greater than or equal to value
+func (e *EmbeddedResource) Gte(column string, value interface{}) *EmbeddedResource {
+	return e.addParam(column, fmt.Sprintf("gte.%v", value))
+}
+
+// Lt matches only embedded rows where column is less than value
+func (e *EmbeddedResource) Lt(column string, value interface{}) *EmbeddedResource {
+	return e.addParam(column, fmt.Sprintf("lt.%v", value))
+}
+
+// Lte matches only embedded rows where column is less than or equal to value
+func (e *EmbeddedResource) Lte(column string, value interface{}) *EmbeddedResource {
+	return e.addParam(column, fmt.Sprintf("lte.%v", value))
+}
+
+// Is matches only embedded rows where column IS value
+func (e *EmbeddedResource) Is(column string, value interface{}) *EmbeddedResource {
+	return e.addParam(column, fmt.Sprintf("is.%v", value))
+}
+
+// In matches only embedded rows where column is included in the values array
+func (e *EmbeddedResource) In(column string, values []interface{}) *EmbeddedResource {
+	return e.addParam(column, fmt.Sprintf("in.(%s)", strings.Join(inListValues(values), ",")))
+}
+
+// Or matches only embedded rows which satisfy at least one of the filters
+func (e *EmbeddedResource) Or(filters string) *EmbeddedResource {
+	return e.addParam("or", fmt.Sprintf("(%s)", filters))
+}
+
+// Order orders the embedded rows by column. ReferencedTable in opts is ignored.
+func (e *EmbeddedResource) Order(column string, opts *OrderOptions) *EmbeddedResource {
+	if opts == nil {
+		opts = &OrderOptions{Ascending: true}
+	}
+	e.order = append(e.order, formatOrder(column, opts))
+	return e
+}
+
+// Limit limits the number of embedded rows per parent row
+func (e *EmbeddedResource) Limit(count int) *EmbeddedResource {
+	return e.setParam("limit", strconv.Itoa(count))
+}
+
+// Offset skips the first count embedded rows of each parent row
+func (e *EmbeddedResource) Offset(count int) *EmbeddedResource {
+	return e.setParam("offset", strconv.Itoa(count))
+}
+
+func (e *EmbeddedResource) addParam(key, value string) *EmbeddedResource {
+	e.params = append(e.params, embedParam{key: key, value: value})
+	return e
+}
+
+func (e *EmbeddedResource) setParam(key, value string) *EmbeddedResource {
+	for i, p := range e.params {
+		if p.key == key {
+			e.params[i].value = value
+			return e
+		}
+	}
+	return e.addParam(key, value)
+}
+
+// path returns the name scoped parameters of the resource are prefixed with
+func (e *EmbeddedResource) path(parent string) string {
+	name := e.relation
+	if e.alias != "" && !e.spread {
+		name = e.alias
+	}
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// applyParams adds the scoped parameters of the resource and of the resources
+// embedded in it to query, returning the first invalid argument given to any
+// of them
+func (e *EmbeddedResource) applyParams(query url.Values, parent string) error {
+	err := e.err
+	path := e.path(parent)
+	for _, p := range e.params {
+		query.Add(path+"."+p.key, p.value)
+	}
+	if len(e.order) > 0 {
+		query.Set(path+".order", strings.Join(e.order, ","))
+	}
+	for _, item := range e.items {
+		if child, ok := item.(*EmbeddedResource); ok {
+			if childErr := child.applyParams(query, path); err == nil {
+				err = childErr
+			}
+		}
+	}
+	return err
+}
+
+func (e *EmbeddedResource) selectString() string {
+	var sb strings.Builder
+	if e.spread {
+		sb.WriteString("...")
+	} else if e.alias != "" {
+		sb.WriteString(e.alias)
+		sb.WriteString(":")
+	}
+	sb.WriteString(e.relation)
+	if e.hint != "" {
+		sb.WriteString("!")
+		sb.WriteString(e.hint)
+	}
+	if e.join != "" {
+		sb.WriteString("!")
+		sb.WriteString(e.join)
+	}
+	sb.WriteString("(")
+	sb.WriteString(Columns(e.items...))
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// Columns renders select items into a select string for Select
+func Columns(items ...SelectItem) string {
+	if len(items) == 0 {
+		return "*"
+	}
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = item.selectString()
+	}
+	return strings.Join(parts, ",")
+}
+
+// SelectItems performs a SELECT query with a structured select list. Filters,
+// ordering and limits set on embedded resources are added to the query.
+func (q *QueryBuilder[T]) SelectItems(opts *SelectOptions, items ...SelectItem) *FilterBuilder[[]T] {
+	f := q.Select(Columns(items...), opts)
+
+	query := f.url.Query()
+	for _, item := range items {
+		if embed, ok := item.(*EmbeddedResource); ok {
+			if err := embed.applyParams(query, ""); err != nil {
+				f.setErr(err)
+			}
+		}
+	}
+	f.url.RawQuery = query.Encode()
+	return f
+}
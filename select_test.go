@@ -0,0 +1,133 @@
+package postgrest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []SelectItem
+		expected string
+	}{
+		{
+			name:     "Empty",
+			expected: "*",
+		},
+		{
+			name:     "Plain and aliased columns",
+			items:    []SelectItem{Col("id"), Col("username").As("name")},
+			expected: "id,name:username",
+		},
+		{
+			name:     "Cast column",
+			items:    []SelectItem{Col("age_range").Cast("text")},
+			expected: "age_range::text",
+		},
+		{
+			name:     "Aggregates with grouping column",
+			items:    []SelectItem{Col("status"), Col("amount").Sum(), Col("amount").Avg().As("average"), CountAll()},
+			expected: "status,amount.sum(),average:amount.avg(),count()",
+		},
+		{
+			name:     "Input and result casts",
+			items:    []SelectItem{Col("amount").Cast("int").Sum().Cast("text").As("total"), Col("id").Count(), Col("id").Min(), Col("id").Max()},
+			expected: "total:amount::int.sum()::text,id.count(),id.min(),id.max()",
+		},
+		{
+			name:     "Aggregates in embedded resource",
+			items:    []SelectItem{Col("slug"), Embed("messages", CountAll().As("messages"), Col("id").Max())},
+			expected: "slug,messages(messages:count(),id.max())",
+		},
+		{
+			name:     "Aliased embed of all columns",
+			items:    []SelectItem{Col("*"), Embed("users").As("author")},
+			expected: "*,author:users(*)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Columns(tt.items...))
+		})
+	}
+}
+
+func TestQueryBuilder_SelectItems(t *testing.T) {
+	c := createClient(t)
+	if !mockResponses {
+		t.Skip("requires mocked responses")
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterRegexpResponder("GET", mockPath, func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "status,total:count()", req.URL.Query().Get("select"))
+		return httpmock.NewJsonResponse(200, []map[string]interface{}{
+			{"status": "ONLINE", "total": 3},
+			{"status": "OFFLINE", "total": 2},
+		})
+	})
+
+	var got []struct {
+		Status string `json:"status"`
+		Total  int64  `json:"total"`
+	}
+	_, err := c.From("users").
+		SelectItems(nil, Col("status"), CountAll().As("total")).
+		ExecuteTo(context.Background(), &got)
+	assert.NoError(t, err)
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, "ONLINE", got[0].Status)
+		assert.Equal(t, int64(3), got[0].Total)
+	}
+}
+
+func TestEmbeddedResource(t *testing.T) {
+	t.Run("SelectString", func(t *testing.T) {
+		items := []SelectItem{
+			Col("id"),
+			Embed("users", Col("username")).As("author").Hint("messages_username_fkey").Inner(),
+			Embed("channels", Col("slug")).Hint("channel_id").Spread(),
+			Embed("movie").Left(),
+		}
+		assert.Equal(t, "id,author:users!messages_username_fkey!inner(username),...channels!channel_id(slug),movie!left(*)", Columns(items...))
+	})
+
+	t.Run("ScopedParams", func(t *testing.T) {
+		client := NewClient("http://localhost:3000", "", nil)
+		nullsFirst := true
+
+		f := NewQueryBuilder[map[string]interface{}](client, "channels").SelectItems(nil,
+			Col("slug"),
+			Embed("messages",
+				Col("id"),
+				Embed("users", Col("username")).As("author").Eq("status", "ONLINE"),
+			).Inner().
+				Eq("username", "supabot").
+				In("id", []interface{}{1, 2}).
+				Or("id.eq.1,id.eq.2").
+				Order("inserted_at", &OrderOptions{Ascending: false, NullsFirst: &nullsFirst}).
+				Order("id", nil).
+				Limit(10).
+				Limit(5).
+				Offset(2),
+		)
+
+		query := f.url.Query()
+		assert.Equal(t, "slug,messages!inner(id,author:users(username))", query.Get("select"))
+		assert.Equal(t, []string{"eq.supabot"}, query["messages.username"])
+		assert.Equal(t, []string{"in.(1,2)"}, query["messages.id"])
+		assert.Equal(t, "(id.eq.1,id.eq.2)", query.Get("messages.or"))
+		assert.Equal(t, "inserted_at.desc.nullsfirst,id.asc", query.Get("messages.order"))
+		assert.Equal(t, []string{"5"}, query["messages.limit"])
+		assert.Equal(t, "2", query.Get("messages.offset"))
+		assert.Equal(t, "eq.ONLINE", query.Get("messages.author.status"))
+	})
+}
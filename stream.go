@@ -0,0 +1,62 @@
+package postgrest
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"iter"
+)
+
+// StreamJSON returns a request body that encodes the values of seq as a JSON
+// array while the request is being sent. Pass it to Insert, Upsert or Rpc to
+// import more rows than fit in memory; it is sent with chunked transfer
+// encoding.
+//
+// seq is consumed by a separate goroutine, which stops when the request ends.
+// A body can only be sent once.
+func StreamJSON[T any](seq iter.Seq[T]) io.ReadCloser {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeJSONArray(writer, seq))
+	}()
+	return reader
+}
+
+// StreamJSONChan is like StreamJSON for the values received from ch until it
+// is closed. Stop sending on ch once the request has failed, as nothing
+// drains it anymore.
+func StreamJSONChan[T any](ch <-chan T) io.ReadCloser {
+	return StreamJSON(func(yield func(T) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	})
+}
+
+// writeJSONArray writes the values of seq to w as a JSON array
+func writeJSONArray[T any](w io.Writer, seq iter.Seq[T]) error {
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+
+	if err := buffered.WriteByte('['); err != nil {
+		return err
+	}
+	first := true
+	for v := range seq {
+		if !first {
+			if err := buffered.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		first = false
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+	}
+	if err := buffered.WriteByte(']'); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
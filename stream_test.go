@@ -0,0 +1,117 @@
+package postgrest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamJSON(t *testing.T) {
+	c := createClient(t)
+	if !mockResponses {
+		t.Skip("requires mocked responses")
+	}
+
+	t.Run("Insert", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterRegexpResponder("POST", mockPath, func(req *http.Request) (*http.Response, error) {
+			// unknown length, so the body is sent chunked
+			assert.Equal(t, int64(0), req.ContentLength)
+			assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+			var rows []map[string]interface{}
+			if err := json.NewDecoder(req.Body).Decode(&rows); err != nil {
+				return nil, err
+			}
+			assert.Len(t, rows, 10000)
+			assert.Equal(t, "user9999", rows[9999]["username"])
+			return httpmock.NewStringResponse(201, ""), nil
+		})
+
+		users := func(yield func(map[string]interface{}) bool) {
+			for i := 0; i < 10000; i++ {
+				if !yield(map[string]interface{}{"username": fmt.Sprintf("user%d", i)}) {
+					return
+				}
+			}
+		}
+		response, err := c.From("users").Insert(StreamJSON(users), nil).Execute(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 201, response.Status)
+	})
+
+	t.Run("Channel", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		var rows []int
+		httpmock.RegisterRegexpResponder("POST", mockPath, func(req *http.Request) (*http.Response, error) {
+			err := json.NewDecoder(req.Body).Decode(&rows)
+			return httpmock.NewStringResponse(201, ""), err
+		})
+
+		ch := make(chan int)
+		go func() {
+			defer close(ch)
+			for i := 1; i <= 3; i++ {
+				ch <- i
+			}
+		}()
+		_, err := c.From("numbers").Insert(StreamJSONChan(ch), nil).Execute(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, rows)
+	})
+
+	t.Run("Reader", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		var body []byte
+		httpmock.RegisterRegexpResponder("POST", mockPath, func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "text/csv", req.Header.Get("Content-Type"))
+			var err error
+			body, err = io.ReadAll(req.Body)
+			return httpmock.NewStringResponse(201, ""), err
+		})
+
+		csv := "username,status\nkiwicopple,ONLINE\n"
+		_, err := c.From("users").Insert(strings.NewReader(csv), nil).
+			SetHeader("Content-Type", "text/csv").
+			Execute(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, csv, string(body))
+	})
+
+	t.Run("StopsWhenRequestIsNotSent", func(t *testing.T) {
+		stopped := make(chan struct{})
+		endless := func(yield func(int) bool) {
+			defer close(stopped)
+			for i := 0; ; i++ {
+				if !yield(i) {
+					return
+				}
+			}
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := c.From("numbers").Insert(StreamJSON(endless), nil).Execute(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("producer did not stop")
+		}
+	})
+}
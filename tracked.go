@@ -0,0 +1,181 @@
+package postgrest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Tracked is a row along with a snapshot of its columns as loaded, so that
+// Save only sends the columns that were modified since
+type Tracked[T any] struct {
+	Row      T
+	snapshot map[string]json.RawMessage
+	err      error
+}
+
+// Track returns row, tracking changes made to it from now on. Its columns
+// are those of its JSON encoding.
+func Track[T any](row T) *Tracked[T] {
+	t := &Tracked[T]{Row: row}
+	t.snapshot, t.err = encodeColumns(row)
+	return t
+}
+
+// TrackRows tracks each of rows
+func TrackRows[T any](rows []T) []*Tracked[T] {
+	tracked := make([]*Tracked[T], len(rows))
+	for i, row := range rows {
+		tracked[i] = Track(row)
+	}
+	return tracked
+}
+
+// ExecuteTracked executes a select query and tracks the returned rows
+func ExecuteTracked[T any](ctx context.Context, b *Builder[[]T]) ([]*Tracked[T], error) {
+	response, err := b.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, response.Error
+	}
+	return TrackRows(response.Data), nil
+}
+
+// Changes returns the columns of Row whose JSON encoding differs from the
+// snapshot. Columns no longer encoded, such as a pointer with omitempty set
+// back to nil, are changed to null.
+func (t *Tracked[T]) Changes() (map[string]json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	current, err := encodeColumns(t.Row)
+	if err != nil {
+		return nil, err
+	}
+	changes := make(map[string]json.RawMessage)
+	for column, value := range current {
+		if previous, ok := t.snapshot[column]; !ok || !bytes.Equal(previous, value) {
+			changes[column] = value
+		}
+	}
+	for column, previous := range t.snapshot {
+		if _, ok := current[column]; !ok && !bytes.Equal(previous, []byte("null")) {
+			changes[column] = json.RawMessage("null")
+		}
+	}
+	return changes, nil
+}
+
+// SaveOptions contains options for Tracked.Save
+type SaveOptions struct {
+	// PrimaryKey lists the primary key columns, which default to the struct
+	// fields tagged `postgrest:"pk"`
+	PrimaryKey []string
+	// VersionColumn makes Save a versioned update, which returns ErrConflict
+	// unless the column, such as a version number or updated_at timestamp,
+	// still holds its loaded value
+	VersionColumn string
+}
+
+// ErrNoRowMatched is returned by Save when no row has the loaded primary key,
+// because the row was deleted or its key was changed since it was loaded
+var ErrNoRowMatched = errors.New("no row matched")
+
+// Save updates the changed columns of Row with a PATCH filtered by the
+// loaded primary key and, with VersionColumn, the loaded version. Nothing is
+// sent when there are no changes. Row and the snapshot are then replaced by
+// the updated row, which picks up columns set by the database such as a
+// bumped version.
+//
+// Save returns ErrConflict when the version changed, and ErrNoRowMatched
+// when no row has the loaded primary key.
+func (t *Tracked[T]) Save(ctx context.Context, q *QueryBuilder[T], opts *SaveOptions) error {
+	if opts == nil {
+		opts = &SaveOptions{}
+	}
+	changes, err := t.Changes()
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	// The row is identified by its values as loaded, in case they changed
+	columns, _, err := primaryKeyFilters(t.Row, opts.PrimaryKey)
+	if err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+	loaded, err := decodeColumns(t.snapshot)
+	if err != nil {
+		return err
+	}
+	columns, values, err := primaryKeyFilters(loaded, columns)
+	if err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+
+	updateOpts := &UpdateOptions{Returning: "representation"}
+	if opts.VersionColumn != "" {
+		version, ok := loaded[opts.VersionColumn]
+		if !ok {
+			return fmt.Errorf("save: version column %s is missing", opts.VersionColumn)
+		}
+		updateOpts.VersionColumn, updateOpts.Version = opts.VersionColumn, version
+	}
+
+	// Only the table of q is used, so the builder of the select can be reused
+	update := q.clone()
+	update.url.RawQuery = ""
+	f := update.Update(changes, updateOpts)
+	for i, column := range columns {
+		f.Eq(column, values[i])
+	}
+	response, err := retype[[]T](f.Builder).Execute(ctx)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	if len(response.Data) == 0 {
+		return fmt.Errorf("save: %w", ErrNoRowMatched)
+	}
+
+	t.Row = response.Data[0]
+	t.snapshot, t.err = encodeColumns(t.Row)
+	return t.err
+}
+
+// encodeColumns encodes row as a JSON object
+func encodeColumns(row interface{}) (map[string]json.RawMessage, error) {
+	encoded, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+	var columns map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &columns); err != nil || columns == nil {
+		return nil, fmt.Errorf("cannot track %T: rows must encode to JSON objects", row)
+	}
+	return columns, nil
+}
+
+// decodeColumns decodes the columns of a snapshot, keeping numbers as
+// written
+func decodeColumns(columns map[string]json.RawMessage) (map[string]interface{}, error) {
+	decoded := make(map[string]interface{}, len(columns))
+	for column, raw := range columns {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		decoded[column] = value
+	}
+	return decoded, nil
+}
@@ -0,0 +1,111 @@
+package postgrest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type trackedUser struct {
+	ID       int64    `json:"id" postgrest:"pk"`
+	Username string   `json:"username"`
+	Status   string   `json:"status"`
+	Tags     []string `json:"tags"`
+	Version  int      `json:"version"`
+	Nickname *string  `json:"nickname,omitempty"`
+}
+
+func TestTracked(t *testing.T) {
+	c := createClient(t)
+	if !mockResponses {
+		t.Skip("requires mocked responses")
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterRegexpResponder("GET", mockPath, httpmock.NewJsonResponderOrPanic(200, []trackedUser{
+		{ID: 1234567, Username: "kiwicopple", Status: "ONLINE", Tags: []string{"a"}, Version: 3},
+	}))
+
+	var query, body string
+	httpmock.RegisterRegexpResponder("PATCH", mockPath, func(req *http.Request) (*http.Response, error) {
+		query = req.URL.RawQuery
+		encoded, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = string(encoded)
+		if req.URL.Query().Has("version") {
+			assert.Contains(t, req.Header.Get("Prefer"), "max-affected=1")
+		}
+		if req.URL.Query().Get("version") != "eq.3" {
+			resp, _ := httpmock.NewJsonResponse(200, []trackedUser{})
+			resp.Header.Set("Content-Range", "*/0")
+			return resp, nil
+		}
+		resp, _ := httpmock.NewJsonResponse(200, []trackedUser{
+			{ID: 1234567, Username: "kiwicopple", Status: "OFFLINE", Tags: []string{"a", "b"}, Version: 4},
+		})
+		resp.Header.Set("Content-Range", "0-0/1")
+		return resp, nil
+	})
+
+	users := NewQueryBuilder[trackedUser](c, "users")
+	tracked, err := ExecuteTracked(context.Background(), users.Select("*", nil).Eq("id", 1234567).Builder)
+	assert.NoError(t, err)
+	if !assert.Len(t, tracked, 1) {
+		return
+	}
+	user := tracked[0]
+
+	// Nothing is sent without changes
+	assert.NoError(t, user.Save(context.Background(), users, nil))
+	assert.Equal(t, 0, httpmock.GetCallCountInfo()["PATCH =~"+mockPath.String()])
+
+	user.Row.Status = "OFFLINE"
+	user.Row.Tags = append(user.Row.Tags, "b")
+	changes, err := user.Changes()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]json.RawMessage{
+		"status": json.RawMessage(`"OFFLINE"`),
+		"tags":   json.RawMessage(`["a","b"]`),
+	}, changes)
+
+	err = user.Save(context.Background(), users, &SaveOptions{VersionColumn: "version"})
+	assert.NoError(t, err)
+	assert.Equal(t, "id=eq.1234567&version=eq.3", query)
+	assert.JSONEq(t, `{"status":"OFFLINE","tags":["a","b"]}`, body)
+	assert.Equal(t, 4, user.Row.Version)
+	changes, _ = user.Changes()
+	assert.Empty(t, changes)
+
+	t.Run("RemovedColumn", func(t *testing.T) {
+		nickname := "kiwi"
+		removed := Track(trackedUser{ID: 1234567, Nickname: &nickname})
+		removed.Row.Nickname = nil
+		changes, err := removed.Changes()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]json.RawMessage{"nickname": json.RawMessage("null")}, changes)
+	})
+
+	t.Run("Stale", func(t *testing.T) {
+		stale := Track(trackedUser{ID: 1234567, Version: 2})
+		stale.Row.Status = "AWAY"
+		err := stale.Save(context.Background(), users, &SaveOptions{VersionColumn: "version"})
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.Equal(t, "AWAY", stale.Row.Status)
+	})
+
+	t.Run("Deleted", func(t *testing.T) {
+		deleted := Track(trackedUser{ID: 7, Version: 1})
+		deleted.Row.Status = "AWAY"
+		err := deleted.Save(context.Background(), users, nil)
+		assert.True(t, errors.Is(err, ErrNoRowMatched))
+	})
+}
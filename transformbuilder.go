@@ -35,7 +35,7 @@ func (t *TransformBuilder[T]) Select(columns string) *FilterBuilder[T] {
 	query := t.url.Query()
 	query.Set("select", cleaned)
 	t.url.RawQuery = query.Encode()
-	t.headers.Add("Prefer", "return=representation")
+	setPreference(t.headers, "return", "representation")
 
 	return &FilterBuilder[T]{Builder: t.Builder}
 }
@@ -51,6 +51,20 @@ func (t *TransformBuilder[T]) Order(column string, opts *OrderOptions) *Transfor
 		key = opts.ReferencedTable + ".order"
 	}
 
+	query := t.url.Query()
+	existingOrder := query.Get(key)
+	orderValue := formatOrder(column, opts)
+	if existingOrder != "" {
+		orderValue = existingOrder + "," + orderValue
+	}
+
+	query.Set(key, orderValue)
+	t.url.RawQuery = query.Encode()
+	return t
+}
+
+// formatOrder formats a single term of an order parameter
+func formatOrder(column string, opts *OrderOptions) string {
 	ascendingStr := "desc"
 	if opts.Ascending {
 		ascendingStr = "asc"
@@ -65,16 +79,7 @@ func (t *TransformBuilder[T]) Order(column string, opts *OrderOptions) *Transfor
 		}
 	}
 
-	query := t.url.Query()
-	existingOrder := query.Get(key)
-	orderValue := fmt.Sprintf("%s.%s%s", column, ascendingStr, nullsStr)
-	if existingOrder != "" {
-		orderValue = existingOrder + "," + orderValue
-	}
-
-	query.Set(key, orderValue)
-	t.url.RawQuery = query.Encode()
-	return t
+	return fmt.Sprintf("%s.%s%s", column, ascendingStr, nullsStr)
 }
 
 // OrderOptions contains options for ordering
@@ -165,33 +170,13 @@ func (t *TransformBuilder[T]) MaybeSingle() *Builder[T] {
 // CSV returns data as a string in CSV format
 func (t *TransformBuilder[T]) CSV() *Builder[string] {
 	t.headers.Set("Accept", "text/csv")
-	return &Builder[string]{
-		method:             t.method,
-		url:                t.url,
-		headers:            t.headers,
-		schema:             t.schema,
-		body:               t.body,
-		shouldThrowOnError: t.shouldThrowOnError,
-		signal:             t.signal,
-		client:             t.client,
-		isMaybeSingle:      t.isMaybeSingle,
-	}
+	return retype[string](t.Builder)
 }
 
 // GeoJSON returns data as an object in GeoJSON format
 func (t *TransformBuilder[T]) GeoJSON() *Builder[map[string]interface{}] {
 	t.headers.Set("Accept", "application/geo+json")
-	return &Builder[map[string]interface{}]{
-		method:             t.method,
-		url:                t.url,
-		headers:            t.headers,
-		schema:             t.schema,
-		body:               t.body,
-		shouldThrowOnError: t.shouldThrowOnError,
-		signal:             t.signal,
-		client:             t.client,
-		isMaybeSingle:      t.isMaybeSingle,
-	}
+	return retype[map[string]interface{}](t.Builder)
 }
 
 // ExplainOptions contains options for explain
@@ -237,28 +222,18 @@ func (t *TransformBuilder[T]) Explain(opts *ExplainOptions) *Builder[interface{}
 	acceptValue := fmt.Sprintf("application/vnd.pgrst.plan+%s; for=\"%s\"; options=%s;", opts.Format, forMediatype, optionsStr)
 	t.headers.Set("Accept", acceptValue)
 
-	return &Builder[interface{}]{
-		method:             t.method,
-		url:                t.url,
-		headers:            t.headers,
-		schema:             t.schema,
-		body:               t.body,
-		shouldThrowOnError: t.shouldThrowOnError,
-		signal:             t.signal,
-		client:             t.client,
-		isMaybeSingle:      t.isMaybeSingle,
-	}
+	return retype[interface{}](t.Builder)
 }
 
 // Rollback rolls back the query
 func (t *TransformBuilder[T]) Rollback() *TransformBuilder[T] {
-	t.headers.Add("Prefer", "tx=rollback")
+	setPreference(t.headers, "tx", "rollback")
 	return t
 }
 
 // MaxAffected sets the maximum number of rows that can be affected by the query
 func (t *TransformBuilder[T]) MaxAffected(value int) *TransformBuilder[T] {
-	t.headers.Add("Prefer", "handling=strict")
-	t.headers.Add("Prefer", fmt.Sprintf("max-affected=%d", value))
+	setPreference(t.headers, "handling", "strict")
+	setPreference(t.headers, "max-affected", strconv.Itoa(value))
 	return t
 }
@@ -128,7 +128,7 @@ func TestTransformBuilder_Rollback(t *testing.T) {
 		defer httpmock.DeactivateAndReset()
 
 		httpmock.RegisterRegexpResponder("GET", mockPath, func(req *http.Request) (*http.Response, error) {
-			prefer := req.Header.Values("Prefer")
+			prefer := req.Header.Get("Prefer")
 			assert.Contains(t, prefer, "tx=rollback")
 			resp, _ := httpmock.NewJsonResponse(200, []interface{}{})
 			return resp, nil
@@ -155,7 +155,7 @@ func TestTransformBuilder_MaxAffected(t *testing.T) {
 		defer httpmock.DeactivateAndReset()
 
 		httpmock.RegisterRegexpResponder("GET", mockPath, func(req *http.Request) (*http.Response, error) {
-			prefer := req.Header.Values("Prefer")
+			prefer := req.Header.Get("Prefer")
 			assert.Contains(t, prefer, "handling=strict")
 			assert.Contains(t, prefer, "max-affected=10")
 			resp, _ := httpmock.NewJsonResponse(200, []interface{}{})
@@ -0,0 +1,202 @@
+package postgrest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TSQuery is a full-text search query built from terms and operators and
+// rendered in to_tsquery syntax, for use with TextSearchQuery. Terms are
+// quoted, so user input can't inject operators.
+type TSQuery struct {
+	op       string
+	text     string
+	children []TSQuery
+}
+
+// Operator precedence in to_tsquery, from loosest to tightest
+const (
+	tsPrecOr = iota
+	tsPrecAnd
+	tsPrecPhrase
+	tsPrecNot
+	tsPrecTerm
+)
+
+// TSTerm matches the lexeme of word
+func TSTerm(word string) TSQuery {
+	return TSQuery{text: quoteTSLexeme(word)}
+}
+
+// TSPrefix matches lexemes starting with word
+func TSPrefix(word string) TSQuery {
+	return TSQuery{text: quoteTSLexeme(word) + ":*"}
+}
+
+// TSPhrase matches words appearing next to each other in order
+func TSPhrase(words ...string) TSQuery {
+	var q TSQuery
+	for _, word := range words {
+		q = q.FollowedBy(1, TSTerm(word))
+	}
+	return q
+}
+
+// And matches documents matching q and every query in others
+func (q TSQuery) And(others ...TSQuery) TSQuery {
+	return q.join("&", others)
+}
+
+// Or matches documents matching q or any query in others
+func (q TSQuery) Or(others ...TSQuery) TSQuery {
+	return q.join("|", others)
+}
+
+// Not matches documents not matching q
+func (q TSQuery) Not() TSQuery {
+	if q.isZero() {
+		return q
+	}
+	return TSQuery{op: "!", children: []TSQuery{q}}
+}
+
+// FollowedBy matches next appearing distance positions after q, <-> for a
+// distance of 1 and <N> otherwise
+func (q TSQuery) FollowedBy(distance int, next TSQuery) TSQuery {
+	if q.isZero() {
+		return next
+	}
+	if next.isZero() {
+		return q
+	}
+	op := "<->"
+	if distance != 1 {
+		op = fmt.Sprintf("<%d>", distance)
+	}
+	return TSQuery{op: op, children: []TSQuery{q, next}}
+}
+
+func (q TSQuery) join(op string, others []TSQuery) TSQuery {
+	var children []TSQuery
+	for _, child := range append([]TSQuery{q}, others...) {
+		switch {
+		case child.isZero():
+		case child.op == op:
+			children = append(children, child.children...)
+		default:
+			children = append(children, child)
+		}
+	}
+	if len(children) <= 1 {
+		if len(children) == 0 {
+			return TSQuery{}
+		}
+		return children[0]
+	}
+	return TSQuery{op: op, children: children}
+}
+
+func (q TSQuery) isZero() bool {
+	return q.op == "" && q.text == ""
+}
+
+func (q TSQuery) precedence() int {
+	switch q.op {
+	case "":
+		return tsPrecTerm
+	case "!":
+		return tsPrecNot
+	case "&":
+		return tsPrecAnd
+	case "|":
+		return tsPrecOr
+	default:
+		return tsPrecPhrase
+	}
+}
+
+// String returns the query in to_tsquery syntax
+func (q TSQuery) String() string {
+	switch q.op {
+	case "":
+		return q.text
+	case "!":
+		return "!" + q.children[0].wrap(tsPrecNot, false)
+	default:
+		parts := make([]string, len(q.children))
+		for i, child := range q.children {
+			// Phrase operators don't associate, so a nested phrase on the right
+			// keeps its parentheses
+			parts[i] = child.wrap(q.precedence(), i > 0 && q.precedence() == tsPrecPhrase)
+		}
+		return strings.Join(parts, " "+q.op+" ")
+	}
+}
+
+// wrap renders q, parenthesized when it binds more loosely than its parent
+func (q TSQuery) wrap(parent int, strict bool) string {
+	if q.precedence() < parent || (strict && q.precedence() == parent) {
+		return "(" + q.String() + ")"
+	}
+	return q.String()
+}
+
+// quoteTSLexeme quotes word as a to_tsquery lexeme, escaping quotes and
+// backslashes
+func quoteTSLexeme(word string) string {
+	word = strings.ReplaceAll(word, `\`, `\\`)
+	word = strings.ReplaceAll(word, "'", "''")
+	return "'" + word + "'"
+}
+
+// TextSearchType selects the Postgres function PostgREST parses a text
+// search query with
+type TextSearchType string
+
+const (
+	// TextSearchDefault parses the query with to_tsquery
+	TextSearchDefault TextSearchType = ""
+	// TextSearchPlain parses the query with plainto_tsquery
+	TextSearchPlain TextSearchType = "plain"
+	// TextSearchPhrase parses the query with phraseto_tsquery
+	TextSearchPhrase TextSearchType = "phrase"
+	// TextSearchWebsearch parses the query with websearch_to_tsquery
+	TextSearchWebsearch TextSearchType = "websearch"
+)
+
+// operator returns the filter operator for the search type
+func (t TextSearchType) operator() (Operator, error) {
+	switch t {
+	case TextSearchDefault:
+		return OpFts, nil
+	case TextSearchPlain:
+		return OpPlainFts, nil
+	case TextSearchPhrase:
+		return OpPhraseFts, nil
+	case TextSearchWebsearch:
+		return OpWebFts, nil
+	default:
+		return "", fmt.Errorf("unknown text search type %q", string(t))
+	}
+}
+
+// textSearchConfigRegexp matches a text search configuration name,
+// optionally qualified with its schema
+var textSearchConfigRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*(\.[A-Za-z_][A-Za-z0-9_$]*)?$`)
+
+// textSearchFilter returns the filter value for query, checking the search
+// type and configuration
+func textSearchFilter(typ TextSearchType, config, query string) (string, error) {
+	op, err := typ.operator()
+	if err != nil {
+		return "", err
+	}
+	if config == "" {
+		return fmt.Sprintf("%s.%s", op, query), nil
+	}
+	if !textSearchConfigRegexp.MatchString(config) {
+		return "", fmt.Errorf("invalid text search config %q", config)
+	}
+	return fmt.Sprintf("%s(%s).%s", op, config, query), nil
+}
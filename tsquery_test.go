@@ -0,0 +1,78 @@
+package postgrest
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTSQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    TSQuery
+		expected string
+	}{
+		{"Term", TSTerm("fat"), "'fat'"},
+		{"Escaped term", TSTerm(`it's a \ test`), `'it''s a \\ test'`},
+		{"Injection stays quoted", TSTerm("cat' | 'dog"), `'cat'' | ''dog'`},
+		{"Prefix", TSPrefix("supa"), "'supa':*"},
+		{"And", TSTerm("fat").And(TSTerm("cat"), TSTerm("rat")), "'fat' & 'cat' & 'rat'"},
+		{"Or inside and", TSTerm("fat").And(TSTerm("cat").Or(TSTerm("rat"))), "'fat' & ('cat' | 'rat')"},
+		{"And inside or", TSTerm("fat").And(TSTerm("cat")).Or(TSTerm("rat")), "'fat' & 'cat' | 'rat'"},
+		{"Not", TSTerm("fat").And(TSTerm("cat").Not()), "'fat' & !'cat'"},
+		{"Not of group", TSTerm("fat").Or(TSTerm("cat")).Not(), "!('fat' | 'cat')"},
+		{"Phrase", TSPhrase("fat", "cat", "sat"), "'fat' <-> 'cat' <-> 'sat'"},
+		{"Distance", TSTerm("fat").FollowedBy(3, TSTerm("rat")), "'fat' <3> 'rat'"},
+		{"Nested phrase on the right", TSTerm("a").FollowedBy(2, TSPhrase("b", "c")), "'a' <2> ('b' <-> 'c')"},
+		{"Or inside phrase", TSTerm("a").Or(TSTerm("b")).FollowedBy(1, TSTerm("c")), "('a' | 'b') <-> 'c'"},
+		{"Zero values are skipped", TSQuery{}.And(TSTerm("a"), TSQuery{}), "'a'"},
+		{"Empty", TSQuery{}.Or(), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.query.String())
+		})
+	}
+}
+
+func TestFilterBuilder_TextSearch(t *testing.T) {
+	client := NewClient("http://localhost:3000", "", nil)
+
+	t.Run("Types and config", func(t *testing.T) {
+		f := client.From("messages").Select("*", nil).
+			TextSearch("a", "fat cat", &TextSearchOptions{Type: TextSearchPlain, Config: "english"}).
+			TextSearch("b", "fat cat", &TextSearchOptions{Type: TextSearchPhrase}).
+			TextSearch("c", `"fat cat" -rat`, &TextSearchOptions{Type: TextSearchWebsearch, Config: "public.my_config"}).
+			TextSearch("d", "fat & cat", nil).
+			TextSearchQuery("e", TSPrefix("supa").And(TSTerm("base")), "simple")
+
+		query, _ := url.ParseQuery(f.url.RawQuery)
+		assert.Equal(t, "plfts(english).fat cat", query.Get("a"))
+		assert.Equal(t, "phfts.fat cat", query.Get("b"))
+		assert.Equal(t, `wfts(public.my_config)."fat cat" -rat`, query.Get("c"))
+		assert.Equal(t, "fts.fat & cat", query.Get("d"))
+		assert.Equal(t, "fts(simple).'supa':* & 'base'", query.Get("e"))
+		assert.NoError(t, f.err)
+	})
+
+	t.Run("Invalid config", func(t *testing.T) {
+		f := client.From("messages").Select("*", nil).
+			TextSearch("message", "cat", &TextSearchOptions{Config: "english).x"}).
+			TextSearchQuery("message", TSTerm("cat"), "bad config")
+
+		assert.NotContains(t, f.url.RawQuery, "message=")
+		_, err := f.Execute(context.Background())
+		assert.EqualError(t, err, `invalid text search config "english).x"`)
+	})
+
+	t.Run("Unknown type", func(t *testing.T) {
+		f := client.From("messages").Select("*", nil).
+			TextSearch("message", "cat", &TextSearchOptions{Type: "fuzzy"})
+
+		_, err := f.ExecuteTo(context.Background(), &[]map[string]interface{}{})
+		assert.EqualError(t, err, `unknown text search type "fuzzy"`)
+	})
+}
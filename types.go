@@ -1,5 +1,13 @@
 package postgrest
 
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
 // PostgrestResponse represents the response format from PostgREST
 // https://github.com/supabase/supabase-js/issues/32
 type PostgrestResponse[T any] struct {
@@ -8,6 +16,80 @@ type PostgrestResponse[T any] struct {
 	Count      *int64          `json:"count,omitempty"`
 	Status     int             `json:"status"`
 	StatusText string          `json:"statusText"`
+
+	// Header holds the raw response headers
+	Header http.Header `json:"-"`
+	// PreferenceApplied holds the preferences PostgREST reported as honored
+	// in the Preference-Applied header
+	PreferenceApplied Preferences `json:"-"`
+	// Location is the Location header returned for inserts, e.g. with
+	// return=headers-only
+	Location string `json:"-"`
+}
+
+// LocationFilters parses the Location header of an insert response into the
+// primary key columns and values of the created row, suitable for Match
+func (r *PostgrestResponse[T]) LocationFilters() (map[string]string, error) {
+	if r.Location == "" {
+		return nil, fmt.Errorf("response has no Location header")
+	}
+	location, err := url.Parse(r.Location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Location header: %w", err)
+	}
+
+	filters := make(map[string]string)
+	for column, values := range location.Query() {
+		for _, value := range values {
+			eq, ok := strings.CutPrefix(value, "eq.")
+			if !ok {
+				return nil, fmt.Errorf("unexpected filter %s=%s in Location header", column, value)
+			}
+			filters[column] = eq
+		}
+	}
+	return filters, nil
+}
+
+// Preferences is a set of Prefer header preferences such as count=exact or
+// handling=strict, keyed by name
+type Preferences map[string]string
+
+// parsePreferences parses Prefer or Preference-Applied header values, which
+// may each hold several comma separated preferences
+func parsePreferences(values []string) Preferences {
+	prefs := make(Preferences)
+	for _, value := range values {
+		for _, pref := range strings.Split(value, ",") {
+			pref = strings.TrimSpace(pref)
+			if pref == "" {
+				continue
+			}
+			name, val, _ := strings.Cut(pref, "=")
+			prefs[strings.TrimSpace(name)] = strings.TrimSpace(val)
+		}
+	}
+	return prefs
+}
+
+// Has reports whether the preference name=value is in the set
+func (p Preferences) Has(name, value string) bool {
+	v, ok := p[name]
+	return ok && v == value
+}
+
+// String formats the preferences as a single Prefer header value
+func (p Preferences) String() string {
+	prefs := make([]string, 0, len(p))
+	for name, value := range p {
+		if value == "" {
+			prefs = append(prefs, name)
+		} else {
+			prefs = append(prefs, name+"="+value)
+		}
+	}
+	sort.Strings(prefs)
+	return strings.Join(prefs, ", ")
 }
 
 // PostgrestResponseSuccess represents a successful response